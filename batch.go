@@ -0,0 +1,187 @@
+package pangu
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileReport describes what paranoid text spacing did to a single file.
+type FileReport struct {
+	Filename string `json:"filename"`
+	Changed  bool   `json:"changed"`
+
+	// Insertions is the number of places where whitespace was added
+	// to the file.
+	Insertions int `json:"insertions"`
+
+	// Rules maps the name of each rule to the number of changes it made.
+	Rules map[string]int `json:"rules"`
+}
+
+// Report describes a batch run of SpacingFiles or SpacingDir.
+type Report struct {
+	Files []FileReport `json:"files"`
+}
+
+// WriteJSON writes r to w as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	b = append(b, '\n')
+	_, err = w.Write(b)
+
+	return err
+}
+
+// SpacingFiles performs paranoid text spacing on each named file and
+// rewrites the files that changed. It returns a Report of every file it
+// processed; on error, the report covers the files done so far.
+// A file that doesn't look like UTF-8 text yields ErrBinaryFile or
+// ErrUnsupportedEncoding and is left as it is.
+func SpacingFiles(filenames ...string) (*Report, error) {
+	return defaultSpacer.SpacingFiles(filenames...)
+}
+
+// SpacingDir walks the file tree rooted at root and performs paranoid
+// text spacing on every text file in it, like SpacingFiles. Binary
+// files and directories whose names start with "." are skipped, and so
+// is what the .panguignore files in the tree list, with the patterns of
+// each file applying to its own directory as in .gitignore.
+func SpacingDir(root string) (*Report, error) {
+	return defaultSpacer.SpacingDir(root)
+}
+
+// SpacingFiles is like the package-level SpacingFiles but uses the
+// rules and options of s.
+func (s *Spacer) SpacingFiles(filenames ...string) (*Report, error) {
+	report := &Report{}
+
+	for _, filename := range filenames {
+		result, err := s.spacingFileInPlace(filename)
+		if err != nil {
+			return report, err
+		}
+		report.Files = append(report.Files, result)
+	}
+
+	return report, nil
+}
+
+// SpacingDir is like the package-level SpacingDir but uses the rules
+// and options of s.
+func (s *Spacer) SpacingDir(root string) (*Report, error) {
+	var filenames []string
+
+	root = filepath.Clean(root)
+	ignoreFiles := map[string][]ignoreRule{}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && ignored(ignoreFiles, root, path, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			ignoreFiles[path], err = readIgnoreFile(path)
+			return err
+		}
+		if !info.Mode().IsRegular() || info.Name() == ignoreFileName {
+			return nil
+		}
+
+		text, err := isTextFile(path)
+		if err != nil {
+			return err
+		}
+		if text {
+			filenames = append(filenames, path)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return &Report{}, err
+	}
+
+	return s.SpacingFiles(filenames...)
+}
+
+// isTextFile reports whether the file named by filename passes sniff.
+func isTextFile(filename string) (bool, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	switch err := sniff(bufio.NewReader(f)); err {
+	case nil:
+		return true, nil
+	case ErrBinaryFile, ErrUnsupportedEncoding:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// spacingFileInPlace performs paranoid text spacing on the file named by
+// filename line by line, rewrites it if anything changed and reports
+// what was done.
+func (s *Spacer) spacingFileInPlace(filename string) (FileReport, error) {
+	result := FileReport{Filename: filename, Rules: map[string]int{}}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return result, err
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return result, err
+	}
+
+	var buf bytes.Buffer
+
+	br := bufio.NewReader(bytes.NewReader(data))
+	if err := sniff(br); err != nil {
+		return result, err
+	}
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return result, err
+		}
+
+		spaced := s.spacing(line, result.Rules)
+		result.Insertions += insertions(diff(line, spaced))
+		buf.WriteString(spaced)
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	if bytes.Equal(buf.Bytes(), data) {
+		return result, nil
+	}
+	result.Changed = true
+
+	return result, ioutil.WriteFile(filename, buf.Bytes(), info.Mode())
+}
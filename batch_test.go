@@ -0,0 +1,172 @@
+package pangu_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/vinta/pangu"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// copyTree copies the fixture tree rooted at src into a new temporary
+// directory, so tests are free to rewrite files in place.
+func copyTree(src string) string {
+	dst, err := ioutil.TempDir("", "pangu")
+	checkError(err)
+
+	err = filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(src, path)
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, data, info.Mode())
+	})
+	checkError(err)
+
+	return dst
+}
+
+func (suite *PanguTestSuite) TestSpacingDir() {
+	root := copyTree("_fixtures/tree")
+	defer os.RemoveAll(root)
+
+	report, err := pangu.SpacingDir(root)
+	suite.Nil(err)
+	suite.Equal(report.Files, []pangu.FileReport{
+		{
+			Filename:   filepath.Join(root, "docs/unspaced.txt"),
+			Changed:    true,
+			Insertions: 3,
+			Rules:      map[string]int{"ans": 3},
+		},
+		{
+			Filename:   filepath.Join(root, "readme.txt"),
+			Changed:    true,
+			Insertions: 4,
+			Rules:      map[string]int{"ans": 3, "hash": 1},
+		},
+		{
+			Filename:   filepath.Join(root, "spaced/spaced.txt"),
+			Changed:    false,
+			Insertions: 0,
+			Rules:      map[string]int{},
+		},
+	})
+
+	suite.Equal(md5Of(filepath.Join(root, "docs/unspaced.txt")), md5Of("_fixtures/test_file.expected.txt"))
+	suite.Equal(md5Of(filepath.Join(root, "docs/.cache/unspaced.txt")), md5Of("_fixtures/test_file.txt"))
+	suite.Equal(md5Of(filepath.Join(root, "docs/image.bin")), md5Of("_fixtures/test_file.bin"))
+
+	// a second run has nothing left to do
+	report, err = pangu.SpacingDir(root)
+	suite.Nil(err)
+	for _, result := range report.Files {
+		suite.False(result.Changed)
+	}
+}
+
+func (suite *PanguTestSuite) TestSpacingDirIgnore() {
+	root := copyTree("_fixtures/tree")
+	defer os.RemoveAll(root)
+
+	unspaced := []byte("當你凝視著bug，bug也凝視著你\n")
+	checkError(ioutil.WriteFile(filepath.Join(root, ".panguignore"), []byte("# generated\ndocs/\n*.md\n"), 0644))
+	checkError(ioutil.WriteFile(filepath.Join(root, "notes.md"), unspaced, 0644))
+	checkError(os.MkdirAll(filepath.Join(root, "spaced/more"), 0755))
+	checkError(ioutil.WriteFile(filepath.Join(root, "spaced/.panguignore"), []byte("!keep.md\n/more/*.txt\n"), 0644))
+	checkError(ioutil.WriteFile(filepath.Join(root, "spaced/keep.md"), unspaced, 0644))
+	checkError(ioutil.WriteFile(filepath.Join(root, "spaced/drop.md"), unspaced, 0644))
+	checkError(ioutil.WriteFile(filepath.Join(root, "spaced/more/skip.txt"), unspaced, 0644))
+
+	report, err := pangu.SpacingDir(root)
+	suite.Nil(err)
+
+	var filenames []string
+	for _, result := range report.Files {
+		filenames = append(filenames, result.Filename)
+	}
+	suite.Equal(filenames, []string{
+		filepath.Join(root, "readme.txt"),
+		filepath.Join(root, "spaced/keep.md"),
+		filepath.Join(root, "spaced/spaced.txt"),
+	})
+
+	// the ignored files are left alone
+	suite.Equal(md5Of(filepath.Join(root, "docs/unspaced.txt")), md5Of("_fixtures/test_file.txt"))
+	for _, name := range []string{"notes.md", "spaced/drop.md", "spaced/more/skip.txt"} {
+		data, err := ioutil.ReadFile(filepath.Join(root, name))
+		suite.Nil(err)
+		suite.Equal(data, unspaced)
+	}
+}
+
+func (suite *PanguTestSuite) TestReportWriteJSON() {
+	root := copyTree("_fixtures/tree")
+	defer os.RemoveAll(root)
+
+	report, err := pangu.SpacingDir(root)
+	suite.Nil(err)
+
+	var buf bytes.Buffer
+	err = report.WriteJSON(&buf)
+	suite.Nil(err)
+
+	var decoded struct {
+		Files []struct {
+			Filename   string         `json:"filename"`
+			Changed    bool           `json:"changed"`
+			Insertions int            `json:"insertions"`
+			Rules      map[string]int `json:"rules"`
+		} `json:"files"`
+	}
+	err = json.Unmarshal(buf.Bytes(), &decoded)
+	suite.Nil(err)
+	suite.Len(decoded.Files, 3)
+	suite.Equal(decoded.Files[1].Filename, filepath.Join(root, "readme.txt"))
+	suite.True(decoded.Files[1].Changed)
+	suite.Equal(decoded.Files[1].Insertions, 4)
+	suite.Equal(decoded.Files[1].Rules, map[string]int{"ans": 3, "hash": 1})
+	suite.False(decoded.Files[2].Changed)
+}
+
+func (suite *PanguTestSuite) TestSpacingFilesNoSuchFile() {
+	report, err := pangu.SpacingFiles("_fixtures/none.exist")
+	suite.EqualError(err, "stat _fixtures/none.exist: no such file or directory")
+	suite.Len(report.Files, 0)
+}
+
+func (suite *PanguTestSuite) TestSpacingFilesBinary() {
+	dir, err := ioutil.TempDir("", "pangu")
+	checkError(err)
+	defer os.RemoveAll(dir)
+
+	for _, tt := range []struct {
+		name string
+		err  error
+	}{
+		{"test_file.bin", pangu.ErrBinaryFile},
+		{"test_file_utf16.txt", pangu.ErrUnsupportedEncoding},
+	} {
+		data, err := ioutil.ReadFile(filepath.Join("_fixtures", tt.name))
+		checkError(err)
+		filename := filepath.Join(dir, tt.name)
+		checkError(ioutil.WriteFile(filename, data, 0644))
+
+		report, err := pangu.SpacingFiles(filename)
+		suite.Equal(err, tt.err)
+		suite.Len(report.Files, 0)
+
+		after, err := ioutil.ReadFile(filename)
+		checkError(err)
+		suite.Equal(after, data)
+	}
+}
@@ -11,8 +11,31 @@ func BenchmarkSpacingText(b *testing.B) {
 	}
 }
 
+func BenchmarkSpacingTextMetrics(b *testing.B) {
+	s, _ := pangu.NewSpacer(pangu.Options{Metrics: &pangu.Metrics{}})
+	for i := 0; i < b.N; i++ {
+		s.SpacingText("所以,請問Jackey的鼻子有幾個?3.14個!")
+	}
+}
+
 func BenchmarkSpacingFile(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		ExampleSpacingFile()
 	}
 }
+
+// BenchmarkNewSpacer measures what is left of package init, which only
+// builds the default Spacer; compare with BenchmarkCompile.
+func BenchmarkNewSpacer(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		pangu.NewSpacer(pangu.Options{})
+	}
+}
+
+// BenchmarkCompile measures the work deferred from package init to the
+// first call of SpacingText.
+func BenchmarkCompile(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		pangu.Compile()
+	}
+}
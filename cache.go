@@ -0,0 +1,86 @@
+package pangu
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CacheStats describes the activity of the cache of a Spacer.
+type CacheStats struct {
+	Hits   int // calls answered from the cache
+	Misses int // calls that ran the rules
+	Len    int // entries currently held
+}
+
+// CacheStats returns the activity of the cache of s so far. It is the
+// zero value if s was created without a CacheSize.
+func (s *Spacer) CacheStats() CacheStats {
+	if s.cache == nil {
+		return CacheStats{}
+	}
+
+	return s.cache.stats()
+}
+
+// lru is a fixed-size cache of spaced texts keyed by the original text,
+// evicting the least recently used entry when full.
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // of *entry, most recently used first
+	entries map[string]*list.Element
+	hits    int
+	misses  int
+}
+
+type entry struct {
+	key   string
+	value string
+}
+
+func newLRU(size int) *lru {
+	return &lru{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *lru) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return "", false
+	}
+	c.hits++
+	c.order.MoveToFront(e)
+
+	return e.Value.(*entry).value, true
+}
+
+func (c *lru) add(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&entry{key, value})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+}
+
+func (c *lru) stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{Hits: c.hits, Misses: c.misses, Len: c.order.Len()}
+}
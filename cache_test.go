@@ -0,0 +1,69 @@
+package pangu_test
+
+import (
+	"fmt"
+	"github.com/vinta/pangu"
+	"sync"
+)
+
+func (suite *PanguTestSuite) TestCacheHits() {
+	s, err := pangu.NewSpacer(pangu.Options{CacheSize: 2})
+	suite.Nil(err)
+
+	suite.Equal(s.SpacingText(`新八的構造成分有95%是眼鏡`), `新八的構造成分有 95% 是眼鏡`)
+	suite.Equal(s.SpacingText(`新八的構造成分有95%是眼鏡`), `新八的構造成分有 95% 是眼鏡`)
+	suite.Equal(s.CacheStats(), pangu.CacheStats{Hits: 1, Misses: 1, Len: 1})
+}
+
+func (suite *PanguTestSuite) TestCacheEviction() {
+	s, err := pangu.NewSpacer(pangu.Options{CacheSize: 2})
+	suite.Nil(err)
+
+	s.SpacingText(`前面A後面`)
+	s.SpacingText(`前面B後面`)
+	s.SpacingText(`前面A後面`) // hit, B is now the least recently used
+	s.SpacingText(`前面C後面`) // evicts B
+	suite.Equal(s.CacheStats(), pangu.CacheStats{Hits: 1, Misses: 3, Len: 2})
+
+	s.SpacingText(`前面A後面`)
+	suite.Equal(s.CacheStats(), pangu.CacheStats{Hits: 2, Misses: 3, Len: 2})
+
+	suite.Equal(s.SpacingText(`前面B後面`), `前面 B 後面`)
+	suite.Equal(s.CacheStats(), pangu.CacheStats{Hits: 2, Misses: 4, Len: 2})
+}
+
+func (suite *PanguTestSuite) TestCacheDisabled() {
+	s, err := pangu.NewSpacer(pangu.Options{})
+	suite.Nil(err)
+
+	s.SpacingText(`前面A後面`)
+	s.SpacingText(`前面A後面`)
+	suite.Equal(s.CacheStats(), pangu.CacheStats{})
+}
+
+func (suite *PanguTestSuite) TestCacheConcurrent() {
+	s, err := pangu.NewSpacer(pangu.Options{CacheSize: 8})
+	suite.Nil(err)
+
+	var wg sync.WaitGroup
+	results := make([][]string, 8)
+	for g := range results {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				results[g] = append(results[g], s.SpacingText(fmt.Sprintf("第%d章", i%16)))
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		for i, text := range result {
+			suite.Equal(text, fmt.Sprintf("第 %d 章", i%16))
+		}
+	}
+	stats := s.CacheStats()
+	suite.Equal(stats.Hits+stats.Misses, 800)
+	suite.Equal(stats.Len, 8)
+}
@@ -0,0 +1,144 @@
+package pangu
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// CodeHeuristic decides which lines of text look like code, so that
+// they can be left alone by a Spacer; see Options.SkipCode. It's a
+// cheap, per-line alternative to understanding the markup of a file.
+//
+// A line is code if it starts with one of the keywords, or if symbols
+// make up at least SymbolRatio of its non-space runes. Lines mostly
+// made of CJK characters are never code.
+type CodeHeuristic struct {
+	// SymbolRatio is the share of symbols, such as braces, operators
+	// and semicolons, that makes a line code. If it is 0, 0.3 is used.
+	SymbolRatio float64
+
+	// Language picks the keywords of a language: "go", "python",
+	// "javascript" or "shell". If it is empty, the keywords of all of
+	// them are used, but since many of those are common English words,
+	// like "for" and "if", a line starting with one is only code if the
+	// keyword is followed by an opening bracket, or by an identifier and
+	// then "(", "{" or ":=". Any other language makes NewSpacer fail.
+	Language string
+
+	// Keywords, if not empty, replaces the keywords picked by Language.
+	Keywords []string
+}
+
+var codeKeywords = map[string][]string{
+	"go":         {"package", "import", "func", "var", "const", "type", "return", "if", "for", "switch", "case", "defer", "go"},
+	"python":     {"def", "class", "import", "from", "return", "if", "elif", "else", "for", "while", "with", "try", "except", "lambda"},
+	"javascript": {"function", "var", "let", "const", "import", "export", "return", "if", "for", "while", "class"},
+	"shell":      {"if", "then", "fi", "for", "do", "done", "echo", "export", "cd", "sudo"},
+}
+
+// codeSymbols are the runes counted by CodeHeuristic.SymbolRatio.
+const codeSymbols = "{}[]();=<>+-*/&|!:,.\"'`$#%^~\\"
+
+// codeMatcher is a compiled CodeHeuristic.
+type codeMatcher struct {
+	ratio    float64
+	keywords map[string]bool
+	strict   bool // keywords must be followed by code-like text
+}
+
+// newCodeMatcher compiles h. It returns an error if h names an unknown
+// language.
+func newCodeMatcher(h *CodeHeuristic) (*codeMatcher, error) {
+	if _, ok := codeKeywords[h.Language]; h.Language != "" && !ok {
+		return nil, fmt.Errorf("pangu: unknown language %q", h.Language)
+	}
+
+	m := &codeMatcher{ratio: h.SymbolRatio, keywords: map[string]bool{}}
+	if m.ratio == 0 {
+		m.ratio = 0.3
+	}
+
+	keywords := h.Keywords
+	if len(keywords) == 0 {
+		keywords = codeKeywords[h.Language]
+	}
+	if len(keywords) == 0 {
+		m.strict = true
+		for _, kw := range codeKeywords {
+			keywords = append(keywords, kw...)
+		}
+	}
+	for _, kw := range keywords {
+		m.keywords[kw] = true
+	}
+
+	return m, nil
+}
+
+// match reports whether line looks like code.
+func (m *codeMatcher) match(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return false
+	}
+
+	var total, symbols, ideographs int
+	for _, r := range line {
+		switch {
+		case unicode.IsSpace(r):
+			continue
+		case unicode.Is(cjkTable, r):
+			ideographs++
+		case strings.ContainsRune(codeSymbols, r):
+			symbols++
+		}
+		total++
+	}
+	if ideographs*2 >= total {
+		return false
+	}
+
+	// The first word only counts if it's followed by a space or an
+	// opening bracket, as in "func main() {".
+	word := line
+	if i := strings.IndexFunc(line, isNotWordRune); i != -1 {
+		word = ""
+		if strings.IndexByte(" \t({", line[i]) != -1 {
+			word = line[:i]
+		}
+	}
+	if m.keywords[word] && (!m.strict || codeLike(line[len(word):])) {
+		return true
+	}
+
+	return float64(symbols) >= m.ratio*float64(total)
+}
+
+// codeLike reports whether text, which follows a keyword, starts with
+// an opening bracket, or with an identifier followed by "(", "{" or
+// ":=", as in "func main() {" or "for i := 0; i < n; i++ {".
+func codeLike(text string) bool {
+	text = strings.TrimLeft(text, " \t")
+	if text == "" {
+		return false
+	}
+	if strings.IndexByte("([{", text[0]) != -1 {
+		return true
+	}
+
+	i := strings.IndexFunc(text, isNotWordRune)
+	if i <= 0 {
+		return false
+	}
+	if text[i] == '(' {
+		return true
+	}
+	rest := strings.TrimLeft(text[i:], " \t")
+
+	return strings.HasPrefix(rest, "{") || strings.HasPrefix(rest, ":=")
+}
+
+func isNotWordRune(r rune) bool {
+	return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+}
@@ -0,0 +1,79 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+)
+
+func (suite *PanguTestSuite) TestSkipCode() {
+	text := "這是Go的範例：\n" +
+		"func main() {\n" +
+		"\tx := a+b // 計算a+b\n" +
+		"}\n" +
+		"請執行go run main.go看看\n" +
+		"if [ -f 設定.conf ]; then\n" +
+		"for循环是一种结构\n"
+
+	s, err := pangu.NewSpacer(pangu.Options{SkipCode: &pangu.CodeHeuristic{}})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(text), "這是 Go 的範例：\n"+
+		"func main() {\n"+
+		"\tx := a+b // 計算a+b\n"+
+		"}\n"+
+		"請執行 go run main.go 看看\n"+
+		"if [ -f 設定.conf ]; then\n"+
+		"for 循环是一种结构\n")
+}
+
+func (suite *PanguTestSuite) TestSkipCodeSymbolRatio() {
+	line := "x := a+b // 計算a+b"
+
+	s, err := pangu.NewSpacer(pangu.Options{SkipCode: &pangu.CodeHeuristic{}})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(line), line)
+
+	s, err = pangu.NewSpacer(pangu.Options{SkipCode: &pangu.CodeHeuristic{SymbolRatio: 0.6}})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(line), "x := a+b // 計算 a+b")
+}
+
+func (suite *PanguTestSuite) TestSkipCodeKeywords() {
+	line := "return 結果x"
+
+	s, err := pangu.NewSpacer(pangu.Options{SkipCode: &pangu.CodeHeuristic{Language: "go"}})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(line), line)
+
+	s, err = pangu.NewSpacer(pangu.Options{SkipCode: &pangu.CodeHeuristic{Language: "shell"}})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(line), "return 結果 x")
+	suite.Equal(s.SpacingText("echo 結果x"), "echo 結果x")
+
+	s, err = pangu.NewSpacer(pangu.Options{SkipCode: &pangu.CodeHeuristic{Keywords: []string{"SELECT"}}})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText("SELECT 名稱x"), "SELECT 名稱x")
+	suite.Equal(s.SpacingText("echo 結果x"), "echo 結果 x")
+}
+
+func (suite *PanguTestSuite) TestSkipCodeProse() {
+	s, err := pangu.NewSpacer(pangu.Options{SkipCode: &pangu.CodeHeuristic{}})
+	suite.Nil(err)
+
+	// without a Language, keywords only count before code-like text
+	suite.Equal(s.SpacingText("for example, 使用API"), "for example, 使用 API")
+	suite.Equal(s.SpacingText("if needed, 請用API"), "if needed, 請用 API")
+	suite.Equal(s.SpacingText("return 結果x"), "return 結果 x")
+
+	for _, line := range []string{
+		"return f(結果x)",
+		"for i := 0; i < n; i++ { // 迴圈x",
+		"if [ -f 設定.conf ]; then",
+	} {
+		suite.Equal(s.SpacingText(line), line)
+	}
+}
+
+func (suite *PanguTestSuite) TestSkipCodeUnknownLanguage() {
+	s, err := pangu.NewSpacer(pangu.Options{SkipCode: &pangu.CodeHeuristic{Language: "klingon"}})
+	suite.Nil(s)
+	suite.EqualError(err, `pangu: unknown language "klingon"`)
+}
@@ -0,0 +1,46 @@
+package pangu
+
+import (
+	"regexp"
+	"sync"
+)
+
+// Compile, ResetCompile and Compiled give the tests of package
+// pangu_test access to the lazy compilation of the regexps.
+var Compile = compile
+
+// lazyRegexps returns every regexp built by compile.
+func lazyRegexps() []**regexp.Regexp {
+	return []**regexp.Regexp{
+		&cjk_quote, &quote_cjk, &fix_quote, &fix_single_quote,
+		&cjk_hash, &hash_cjk,
+		&cjk_operator_ans, &ans_operator_cjk,
+		&cjk_bracket_cjk, &cjk_bracket, &bracket_cjk, &cjk_open_bracket, &close_bracket_cjk, &fix_bracket,
+		&fix_symbol, &fix_comma, &fix_comma_cjk,
+		&cjk_tab_ans, &ans_tab_cjk,
+		&space_fullwidth_close, &fullwidth_open_space, &cjk_spaces_ans, &ans_spaces_cjk,
+		&cjk_ans, &ans_cjk,
+		&call_open, &quoted_string, &placeholder, &emphasis, &keycap, &symbol,
+	}
+}
+
+// ResetCompile makes the next call that needs the regexps compile them
+// again, as if it were the first one.
+func ResetCompile() {
+	compileOnce = sync.Once{}
+	for _, re := range lazyRegexps() {
+		*re = nil
+	}
+	protected = nil
+}
+
+// Compiled reports whether every regexp built by compile is there.
+func Compiled() bool {
+	for _, re := range lazyRegexps() {
+		if *re == nil {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,55 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+	"sync"
+)
+
+func (suite *PanguTestSuite) TestCompileFirstUse() {
+	pangu.ResetCompile()
+	suite.False(pangu.Compiled())
+
+	s, err := pangu.NewSpacer(pangu.Options{ReplaceBoundaryTabs: true, NormalizeExistingSpacing: true})
+	suite.Nil(err)
+	suite.False(pangu.Compiled())
+
+	suite.Equal(s.SpacingText(`所以,請問Jackey的鼻子有幾個?3.14個!`), `所以, 請問 Jackey 的鼻子有幾個? 3.14 個!`)
+	suite.True(pangu.Compiled())
+}
+
+func (suite *PanguTestSuite) TestCompileConcurrentFirstUse() {
+	pangu.ResetCompile()
+
+	var wg sync.WaitGroup
+	results := make([]string, 16)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = pangu.SpacingText("當你凝視著bug，bug也凝視著你")
+		}(i)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		suite.Equal(result, "當你凝視著 bug，bug 也凝視著你")
+	}
+	suite.True(pangu.Compiled())
+}
+
+func (suite *PanguTestSuite) TestCompileFirstUseMarkdown() {
+	pangu.ResetCompile()
+
+	s, err := pangu.NewSpacer(pangu.Options{Markdown: true})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText("这是**bold**文字"), "这是 **bold** 文字")
+	suite.True(pangu.Compiled())
+}
+
+func (suite *PanguTestSuite) TestCompileFirstUseSpaceSymbols() {
+	pangu.ResetCompile()
+
+	s, err := pangu.NewSpacer(pangu.Options{SpaceSymbols: true})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText("完成✓了"), "完成 ✓ 了")
+}
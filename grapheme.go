@@ -0,0 +1,181 @@
+package pangu
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// SpacingTextGraphemes performs paranoid text spacing on text and
+// returns the result split into grapheme clusters, the user-perceived
+// characters of UAX #29. Joining the clusters gives SpacingText(text).
+func SpacingTextGraphemes(text string) []string {
+	return defaultSpacer.SpacingTextGraphemes(text)
+}
+
+// SpacingTextGraphemes is like the package-level SpacingTextGraphemes
+// but uses the rules and options of s.
+func (s *Spacer) SpacingTextGraphemes(text string) []string {
+	return graphemes(s.SpacingText(text))
+}
+
+// graphemeClass is the Grapheme_Cluster_Break property of a rune,
+// reduced to what graphemes needs.
+type graphemeClass int
+
+const (
+	gcOther graphemeClass = iota
+	gcCR
+	gcLF
+	gcControl
+	gcExtend
+	gcZWJ
+	gcSpacingMark
+	gcRegionalIndicator
+	gcL
+	gcV
+	gcT
+	gcLV
+	gcLVT
+	gcPictographic
+)
+
+// pictographic approximates the Extended_Pictographic property, which
+// the unicode package doesn't provide, with whole blocks where the
+// property only covers parts of them. It includes a few symbols that
+// aren't Extended_Pictographic, like the arrows ↚ and ↛, and leaves out
+// the code points from U+1FC00 on that the property reserves for future
+// emoji. This only matters for rule GB11 of UAX #29, which keeps such a
+// symbol after another one and a ZWJ in the same cluster.
+var pictographic = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x00a9, 0x00a9, 1},
+		{0x00ae, 0x00ae, 1},
+		{0x203c, 0x203c, 1},
+		{0x2049, 0x2049, 1},
+		{0x2122, 0x2122, 1},
+		{0x2139, 0x2139, 1},
+		{0x2194, 0x21aa, 1},
+		{0x231a, 0x23ff, 1},
+		{0x24c2, 0x24c2, 1},
+		{0x25aa, 0x25fe, 1},
+		{0x2600, 0x27bf, 1},
+		{0x2934, 0x2935, 1},
+		{0x2b05, 0x2b55, 1},
+		{0x3030, 0x3030, 1},
+		{0x303d, 0x303d, 1},
+		{0x3297, 0x3297, 1},
+		{0x3299, 0x3299, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1f000, 0x1f1e5, 1},
+		{0x1f200, 0x1f3fa, 1},
+		{0x1f400, 0x1faff, 1},
+	},
+	LatinOffset: 2,
+}
+
+func classOf(r rune) graphemeClass {
+	switch {
+	case r == '\r':
+		return gcCR
+	case r == '\n':
+		return gcLF
+	case r == '\u200d':
+		return gcZWJ
+	case r == '\u200c',
+		r >= 0x1f3fb && r <= 0x1f3ff, // emoji modifiers
+		r >= 0xe0020 && r <= 0xe007f, // tags
+		unicode.In(r, unicode.Mn, unicode.Me):
+		return gcExtend
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return gcControl
+	case unicode.Is(unicode.Mc, r):
+		return gcSpacingMark
+	case r >= 0x1f1e6 && r <= 0x1f1ff:
+		return gcRegionalIndicator
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return gcL
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return gcV
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return gcT
+	case r >= 0xac00 && r <= 0xd7a3:
+		if (r-0xac00)%28 == 0 {
+			return gcLV
+		}
+		return gcLVT
+	case unicode.Is(pictographic, r):
+		return gcPictographic
+	}
+
+	return gcOther
+}
+
+// graphemes splits text into extended grapheme clusters, following the
+// boundary rules of UAX #29 except the rarely used Prepend class.
+func graphemes(text string) []string {
+	var clusters []string
+
+	start := 0
+	prev := gcOther
+	pictSeq := false // in ExtPict Extend*, waiting for a ZWJ
+	zwjPict := false // right after ExtPict Extend* ZWJ
+	riCount := 0
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		cur := classOf(r)
+
+		if i > start && !joins(prev, cur, zwjPict, riCount) {
+			clusters = append(clusters, text[start:i])
+			start = i
+		}
+
+		switch {
+		case cur == gcPictographic:
+			pictSeq, zwjPict = true, false
+		case cur == gcExtend && pictSeq:
+		case cur == gcZWJ && pictSeq:
+			pictSeq, zwjPict = false, true
+		default:
+			pictSeq, zwjPict = false, false
+		}
+		if cur == gcRegionalIndicator {
+			riCount++
+		} else {
+			riCount = 0
+		}
+
+		prev = cur
+		i += size
+	}
+	if start < len(text) {
+		clusters = append(clusters, text[start:])
+	}
+
+	return clusters
+}
+
+// joins reports whether there is no grapheme cluster boundary between
+// a rune of class prev and one of class cur.
+func joins(prev, cur graphemeClass, zwjPict bool, riCount int) bool {
+	switch {
+	case prev == gcCR && cur == gcLF:
+		return true
+	case prev == gcCR, prev == gcLF, prev == gcControl,
+		cur == gcCR, cur == gcLF, cur == gcControl:
+		return false
+	case prev == gcL && (cur == gcL || cur == gcV || cur == gcLV || cur == gcLVT),
+		(prev == gcLV || prev == gcV) && (cur == gcV || cur == gcT),
+		(prev == gcLVT || prev == gcT) && cur == gcT:
+		return true
+	case cur == gcExtend, cur == gcZWJ, cur == gcSpacingMark:
+		return true
+	case zwjPict && cur == gcPictographic:
+		return true
+	case prev == gcRegionalIndicator && cur == gcRegionalIndicator:
+		return riCount%2 == 1
+	}
+
+	return false
+}
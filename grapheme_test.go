@@ -0,0 +1,72 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+	"strings"
+)
+
+func (suite *PanguTestSuite) TestSpacingTextGraphemes() {
+	suite.Equal(pangu.SpacingTextGraphemes(`中文ABC`), []string{"中", "文", " ", "A", "B", "C"})
+	suite.Equal(pangu.SpacingTextGraphemes("中文\r\nA"), []string{"中", "文", "\r\n", "A"})
+	suite.Nil(pangu.SpacingTextGraphemes(""))
+}
+
+func (suite *PanguTestSuite) TestSpacingTextGraphemesCombiningMarks() {
+	// e + combining acute accent
+	suite.Equal(pangu.SpacingTextGraphemes("咖啡cafe\u0301好喝"), []string{"咖", "啡", " ", "c", "a", "f", "e\u0301", " ", "好", "喝"})
+
+	// decomposed Hangul syllables
+	suite.Equal(pangu.SpacingTextGraphemes("\u1112\u1161\u11ab\u1100\u1173\u11af"), []string{"\u1112\u1161\u11ab", "\u1100\u1173\u11af"})
+
+	// ideograph + variation selector
+	suite.Equal(pangu.SpacingTextGraphemes("葛\U000e0100字"), []string{"葛\U000e0100", "字"})
+	suite.Equal(pangu.SpacingTextGraphemes("字\ufe00ab"), []string{"字\ufe00", " ", "a", "b"})
+}
+
+func (suite *PanguTestSuite) TestSpacingTextGraphemesEmoji() {
+	family := "👨\u200d👩\u200d👧"
+	suite.Equal(pangu.SpacingTextGraphemes("我家"+family+"很好"), []string{"我", "家", family, "很", "好"})
+
+	// skin tone modifier, flags and keycaps
+	suite.Equal(pangu.SpacingTextGraphemes("👍🏽🇹🇼🇯🇵"), []string{"👍🏽", "🇹🇼", "🇯🇵"})
+	suite.Equal(pangu.SpacingTextGraphemes("1\ufe0f\u20e3"), []string{"1\ufe0f\u20e3"})
+}
+
+func (suite *PanguTestSuite) TestSpacingTextGraphemesBreakTest() {
+	// cases of GraphemeBreakTest.txt, which pangu leaves unspaced
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"\u0020\u0308\u0020", []string{"\u0020\u0308", "\u0020"}},
+		{"\r\na\n\u0308", []string{"\r\n", "a", "\n", "\u0308"}},
+		{"a\u0308b", []string{"a\u0308", "b"}},
+		{"a\u0903b", []string{"a\u0903", "b"}},
+		{"\u1100\u1100", []string{"\u1100\u1100"}},
+		{"\uac00\u11a8", []string{"\uac00\u11a8"}},
+		{"\uac01\u1160", []string{"\uac01", "\u1160"}},
+		{"a\U0001f1e6\U0001f1e7\U0001f1e8b", []string{"a", "\U0001f1e6\U0001f1e7", "\U0001f1e8", "b"}},
+		{"\U0001f476\U0001f3ff\U0001f476", []string{"\U0001f476\U0001f3ff", "\U0001f476"}},
+		{"a\u200d\U0001f6d1", []string{"a\u200d", "\U0001f6d1"}},
+		{"\U0001f6d1\u200d\U0001f6d1", []string{"\U0001f6d1\u200d\U0001f6d1"}},
+		{"\u2701\u200d\u2701", []string{"\u2701\u200d\u2701"}},
+	}
+
+	for _, tt := range tests {
+		suite.Equal(pangu.SpacingText(tt.text), tt.text)
+		suite.Equal(pangu.SpacingTextGraphemes(tt.text), tt.want)
+	}
+}
+
+func (suite *PanguTestSuite) TestSpacingTextGraphemesJoin() {
+	texts := []string{
+		`當你凝視著bug，bug也凝視著你`,
+		"咖啡cafe\u0301好喝",
+		"我家👨\u200d👩\u200d👧很好",
+		"前面#H2G2後面\r\n",
+	}
+
+	for _, text := range texts {
+		suite.Equal(strings.Join(pangu.SpacingTextGraphemes(text), ""), pangu.SpacingText(text))
+	}
+}
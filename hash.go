@@ -0,0 +1,18 @@
+package pangu
+
+import "crypto/sha256"
+
+// SpacingTextWithHash performs paranoid text spacing on text and returns
+// the result along with its SHA-256 digest, so that identical spaced
+// outputs can be cached or deduplicated by content.
+func SpacingTextWithHash(text string) (string, [32]byte) {
+	return defaultSpacer.SpacingTextWithHash(text)
+}
+
+// SpacingTextWithHash is like the package-level SpacingTextWithHash but
+// uses the rules and options of s.
+func (s *Spacer) SpacingTextWithHash(text string) (string, [32]byte) {
+	spaced := s.SpacingText(text)
+
+	return spaced, sha256.Sum256([]byte(spaced))
+}
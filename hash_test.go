@@ -0,0 +1,19 @@
+package pangu_test
+
+import (
+	"crypto/sha256"
+	"github.com/vinta/pangu"
+)
+
+func (suite *PanguTestSuite) TestSpacingTextWithHash() {
+	spaced, sum := pangu.SpacingTextWithHash(`當你凝視著bug，bug也凝視著你`)
+	suite.Equal(spaced, `當你凝視著 bug，bug 也凝視著你`)
+	suite.Equal(sum, sha256.Sum256([]byte(spaced)))
+
+	// inputs with the same spaced form hash the same
+	_, again := pangu.SpacingTextWithHash(`當你凝視著 bug，bug 也凝視著你`)
+	suite.Equal(again, sum)
+
+	_, other := pangu.SpacingTextWithHash(`當你凝視著bug`)
+	suite.NotEqual(other, sum)
+}
@@ -0,0 +1,130 @@
+package pangu
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Hunk is a contiguous region of text changed by paranoid text spacing.
+// Start and End are byte offsets into the original text, Original is
+// text[Start:End] and Spaced is what replaces it.
+type Hunk struct {
+	Start    int
+	End      int
+	Original string
+	Spaced   string
+}
+
+// SpacingHunks performs paranoid text spacing on text and returns the
+// changes as a list of hunks, in order. Applying all of them with
+// ApplyHunks yields the same result as SpacingText.
+func SpacingHunks(text string) []Hunk {
+	return defaultSpacer.SpacingHunks(text)
+}
+
+// SpacingHunks is like the package-level SpacingHunks but uses the
+// rules and options of s.
+func (s *Spacer) SpacingHunks(text string) []Hunk {
+	return diff(text, s.SpacingText(text))
+}
+
+// ApplyHunks replaces the region of text covered by each hunk with its
+// spaced slice. The hunks must be sorted and must not overlap, as
+// returned by SpacingHunks; a subset of them may be passed to accept
+// only some of the changes.
+func ApplyHunks(text string, hunks []Hunk) string {
+	var buf bytes.Buffer
+
+	last := 0
+	for _, h := range hunks {
+		buf.WriteString(text[last:h.Start])
+		buf.WriteString(h.Spaced)
+		last = h.End
+	}
+	buf.WriteString(text[last:])
+
+	return buf.String()
+}
+
+// MergeHunks joins the hunks of text that are at most distance bytes
+// apart into single hunks covering the text between them, for a diff
+// with fewer, larger changes. The hunks must be sorted and must not
+// overlap; applying the result with ApplyHunks gives the same text as
+// applying hunks.
+func MergeHunks(text string, hunks []Hunk, distance int) []Hunk {
+	var merged []Hunk
+
+	for _, h := range hunks {
+		n := len(merged)
+		if n == 0 || h.Start-merged[n-1].End > distance {
+			merged = append(merged, h)
+			continue
+		}
+
+		last := &merged[n-1]
+		last.Spaced += text[last.End:h.Start] + h.Spaced
+		last.End = h.End
+		last.Original = text[last.Start:last.End]
+	}
+
+	return merged
+}
+
+// insertions returns the number of hunks that only add text.
+func insertions(hunks []Hunk) int {
+	n := 0
+	for _, h := range hunks {
+		if h.Original == "" {
+			n++
+		}
+	}
+
+	return n
+}
+
+// diff aligns the original text a with its spaced form b and returns the
+// regions where they differ. Spacing only inserts or removes whitespace,
+// so a mismatch is resolved by consuming whitespace from b first, then
+// from a.
+func diff(a, b string) []Hunk {
+	var hunks []Hunk
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		ra, na := utf8.DecodeRuneInString(a[i:])
+		rb, nb := utf8.DecodeRuneInString(b[j:])
+		if i < len(a) && j < len(b) && ra == rb {
+			i += na
+			j += nb
+			continue
+		}
+
+		si, sj := i, j
+		for i < len(a) || j < len(b) {
+			ra, na = utf8.DecodeRuneInString(a[i:])
+			rb, nb = utf8.DecodeRuneInString(b[j:])
+			if i < len(a) && j < len(b) && ra == rb {
+				break
+			}
+			switch {
+			case j < len(b) && unicode.IsSpace(rb):
+				j += nb
+			case i < len(a) && unicode.IsSpace(ra):
+				i += na
+			default:
+				i += na
+				j += nb
+			}
+		}
+
+		hunks = append(hunks, Hunk{
+			Start:    si,
+			End:      i,
+			Original: a[si:i],
+			Spaced:   b[sj:j],
+		})
+	}
+
+	return hunks
+}
@@ -0,0 +1,68 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+)
+
+func (suite *PanguTestSuite) TestSpacingHunks() {
+	text := `當你凝視著bug，bug也凝視著你`
+	hunks := pangu.SpacingHunks(text)
+	suite.Equal(hunks, []pangu.Hunk{
+		{Start: 15, End: 15, Original: ``, Spaced: ` `},
+		{Start: 24, End: 24, Original: ``, Spaced: ` `},
+	})
+	suite.Equal(pangu.ApplyHunks(text, hunks), pangu.SpacingText(text))
+
+	// accept only the first change
+	suite.Equal(pangu.ApplyHunks(text, hunks[:1]), `當你凝視著 bug，bug也凝視著你`)
+}
+
+func (suite *PanguTestSuite) TestSpacingHunksRemovedSpaces() {
+	text := `前面( 中文123漢字 )後面`
+	hunks := pangu.SpacingHunks(text)
+	suite.Equal(hunks, []pangu.Hunk{
+		{Start: 6, End: 6, Original: ``, Spaced: ` `},
+		{Start: 7, End: 8, Original: ` `, Spaced: ``},
+		{Start: 14, End: 14, Original: ``, Spaced: ` `},
+		{Start: 17, End: 17, Original: ``, Spaced: ` `},
+		{Start: 23, End: 24, Original: ` `, Spaced: ``},
+		{Start: 25, End: 25, Original: ``, Spaced: ` `},
+	})
+	suite.Equal(pangu.ApplyHunks(text, hunks), pangu.SpacingText(text))
+}
+
+func (suite *PanguTestSuite) TestSpacingHunksNoChange() {
+	suite.Nil(pangu.SpacingHunks(`當你凝視著 bug，bug 也凝視著你`))
+	suite.Equal(pangu.ApplyHunks(`V`, nil), `V`)
+}
+
+func (suite *PanguTestSuite) TestMergeHunks() {
+	text := `前面( 中文123漢字 )後面`
+	hunks := pangu.SpacingHunks(text)
+
+	merged := pangu.MergeHunks(text, hunks, 1)
+	suite.Equal(merged, []pangu.Hunk{
+		{Start: 6, End: 8, Original: `( `, Spaced: ` (`},
+		{Start: 14, End: 14, Original: ``, Spaced: ` `},
+		{Start: 17, End: 17, Original: ``, Spaced: ` `},
+		{Start: 23, End: 25, Original: ` )`, Spaced: `) `},
+	})
+	suite.Equal(pangu.ApplyHunks(text, merged), pangu.SpacingText(text))
+
+	merged = pangu.MergeHunks(text, hunks, 3)
+	suite.Equal(merged, []pangu.Hunk{
+		{Start: 6, End: 8, Original: `( `, Spaced: ` (`},
+		{Start: 14, End: 17, Original: `123`, Spaced: ` 123 `},
+		{Start: 23, End: 25, Original: ` )`, Spaced: `) `},
+	})
+	suite.Equal(pangu.ApplyHunks(text, merged), pangu.SpacingText(text))
+
+	merged = pangu.MergeHunks(text, hunks, len(text))
+	suite.Equal(merged, []pangu.Hunk{
+		{Start: 6, End: 25, Original: `( 中文123漢字 )`, Spaced: ` (中文 123 漢字) `},
+	})
+
+	// nothing is merged at distance 0 unless the hunks touch
+	suite.Equal(pangu.MergeHunks(text, hunks, 0), hunks)
+	suite.Nil(pangu.MergeHunks(text, nil, 10))
+}
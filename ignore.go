@@ -0,0 +1,157 @@
+package pangu
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ignoreFileName is the name of the files listing what SpacingDir skips.
+const ignoreFileName = ".panguignore"
+
+// ignoreRule is a pattern of an ignore file.
+type ignoreRule struct {
+	re      *regexp.Regexp // matches slash-separated paths relative to the file
+	negate  bool           // the pattern started with "!"
+	dirOnly bool           // the pattern ended with "/"
+}
+
+// readIgnoreFile returns the rules of the ignore file in dir, if any.
+func readIgnoreFile(dir string) ([]ignoreRule, error) {
+	f, err := os.Open(filepath.Join(dir, ignoreFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if r, ok := parseIgnoreRule(scanner.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+
+	return rules, scanner.Err()
+}
+
+// parseIgnoreRule parses a line of an ignore file, which follows the
+// gitignore syntax: blank lines and lines starting with "#" are skipped,
+// "!" negates a pattern, a trailing "/" matches only directories, and a
+// pattern with a "/" in it is relative to the directory of the ignore
+// file, while one without matches names at any depth. "*", "?", "[...]"
+// and "**" work as in gitignore.
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	var r ignoreRule
+
+	line = strings.TrimRight(line, " \r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return r, false
+	}
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if line == "" {
+		return r, false
+	}
+
+	var buf strings.Builder
+	buf.WriteString("^")
+	if !anchored {
+		buf.WriteString("(?:.*/)?")
+	}
+
+	pattern := []rune(line)
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			switch {
+			case i+2 < len(pattern) && pattern[i+1] == '*' && pattern[i+2] == '/':
+				buf.WriteString("(?:.*/)?")
+				i += 2
+			case i+1 < len(pattern) && pattern[i+1] == '*':
+				buf.WriteString(".*")
+				i++
+			default:
+				buf.WriteString("[^/]*")
+			}
+		case '?':
+			buf.WriteString("[^/]")
+		case '[':
+			j := i + 1
+			for j < len(pattern) && pattern[j] != ']' {
+				j++
+			}
+			if j == len(pattern) {
+				buf.WriteString("\\[")
+				continue
+			}
+			class := string(pattern[i+1 : j])
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			buf.WriteString("[" + strings.Replace(class, "\\", "\\\\", -1) + "]")
+			i = j
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				buf.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	buf.WriteString("$")
+
+	re, err := regexp.Compile(buf.String())
+	if err != nil {
+		return r, false
+	}
+	r.re = re
+
+	return r, true
+}
+
+// ignored reports whether path is ignored by the ignore files found so
+// far, keyed by directory, from root down to the parent of path. As in
+// gitignore, the last matching pattern wins, and deeper files override
+// shallower ones.
+func ignored(files map[string][]ignoreRule, root, path string, isDir bool) bool {
+	var dirs []string
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == root || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	matched := false
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(dirs[i], path)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+
+		for _, r := range files[dirs[i]] {
+			if (isDir || !r.dirOnly) && r.re.MatchString(rel) {
+				matched = !r.negate
+			}
+		}
+	}
+
+	return matched
+}
@@ -0,0 +1,63 @@
+package pangu
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrEditMismatch is returned by SpacingIncremental when its arguments
+// don't describe a single edit of an already spaced text.
+var ErrEditMismatch = errors.New("pangu: edit does not match inputs")
+
+// SpacingIncremental performs paranoid text spacing on newInput, which
+// is prevInput with prevInput[editStart:editEnd] replaced, given that
+// prevOutput is the spaced form of prevInput. Only the lines touched by
+// the edit are spaced again and spliced into prevOutput, so that editors
+// can keep a document spaced as it is typed. The result is the same as
+// SpacingText(newInput).
+func SpacingIncremental(prevInput, prevOutput, newInput string, editStart, editEnd int) (string, error) {
+	return defaultSpacer.SpacingIncremental(prevInput, prevOutput, newInput, editStart, editEnd)
+}
+
+// SpacingIncremental is like the package-level SpacingIncremental but
+// uses the rules and options of s. prevOutput must have been spaced by
+// s too.
+func (s *Spacer) SpacingIncremental(prevInput, prevOutput, newInput string, editStart, editEnd int) (string, error) {
+	delta := len(newInput) - len(prevInput)
+	if editStart < 0 || editStart > editEnd || editEnd > len(prevInput) || editEnd+delta < editStart {
+		return "", ErrEditMismatch
+	}
+	if prevInput[:editStart] != newInput[:editStart] || prevInput[editEnd:] != newInput[editEnd+delta:] {
+		return "", ErrEditMismatch
+	}
+	if strings.Count(prevInput, "\n") != strings.Count(prevOutput, "\n") {
+		return "", ErrEditMismatch
+	}
+
+	// Lines are spaced independently of each other, so the edit is
+	// widened to the whole lines around it.
+	start := strings.LastIndexByte(prevInput[:editStart], '\n') + 1
+	end := len(prevInput)
+	if i := strings.IndexByte(prevInput[editEnd:], '\n'); i != -1 {
+		end = editEnd + i + 1
+	}
+
+	outStart := lineOffset(prevOutput, strings.Count(prevInput[:start], "\n"))
+	outEnd := len(prevOutput)
+	if end < len(prevInput) {
+		outEnd = lineOffset(prevOutput, strings.Count(prevInput[:end], "\n"))
+	}
+
+	return prevOutput[:outStart] + s.SpacingText(newInput[start:end+delta]) + prevOutput[outEnd:], nil
+}
+
+// lineOffset returns the byte offset of the start of line n of text,
+// counting from 0. Text must have at least n line breaks.
+func lineOffset(text string, n int) int {
+	i := 0
+	for ; n > 0; n-- {
+		i += strings.IndexByte(text[i:], '\n') + 1
+	}
+
+	return i
+}
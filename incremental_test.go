@@ -0,0 +1,43 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+	"strings"
+)
+
+func (suite *PanguTestSuite) TestSpacingIncremental() {
+	prev := "當你凝視著bug，\nbug也凝視著你\n前面(中文123漢字)後面"
+	out := pangu.SpacingText(prev)
+
+	for _, edit := range []struct {
+		start, end int
+		text       string
+	}{
+		{0, 0, "新的"},                   // insert at the start
+		{len(prev), len(prev), "Go語言"}, // append at the end
+		{15, 18, "issue"},              // replace a word
+		{18, 22, ""},                   // delete across a line break
+		{22, 22, "中文\nabc"},            // insert a line break
+		{strings.Index(prev, "前面"), strings.Index(prev, "前面") + 6, "前面Go"},
+		{0, len(prev), "全部the新內容"}, // replace everything
+	} {
+		next := prev[:edit.start] + edit.text + prev[edit.end:]
+		got, err := pangu.SpacingIncremental(prev, out, next, edit.start, edit.end)
+		suite.Nil(err)
+		suite.Equal(got, pangu.SpacingText(next))
+	}
+}
+
+func (suite *PanguTestSuite) TestSpacingIncrementalMismatch() {
+	prev := "當你凝視著bug"
+	out := pangu.SpacingText(prev)
+
+	_, err := pangu.SpacingIncremental(prev, out, "當你凝視著bug", 5, 3)
+	suite.Equal(err, pangu.ErrEditMismatch)
+
+	_, err = pangu.SpacingIncremental(prev, out, "別的文字", 0, 3)
+	suite.Equal(err, pangu.ErrEditMismatch)
+
+	_, err = pangu.SpacingIncremental(prev, out+"\n", prev+"x", len(prev), len(prev))
+	suite.Equal(err, pangu.ErrEditMismatch)
+}
@@ -0,0 +1,62 @@
+package pangu
+
+import (
+	"bytes"
+	"strings"
+)
+
+// stripEmphasis removes the delimiters of the emphasis spans in text.
+// Opening delimiters are marked open, so that spaces go outside of the
+// emphasis span when the delimiters are put back.
+// Underscores within a word, as in snake_case, don't delimit emphasis.
+func stripEmphasis(text string) (string, []cut) {
+	var buf bytes.Buffer
+	var delims []cut
+
+	last := 0
+	for _, m := range emphasis.FindAllStringSubmatchIndex(text, -1) {
+		opening, closing := text[m[2]:m[3]], text[m[6]:m[7]]
+		if opening != closing {
+			continue
+		}
+		if opening[0] == '_' && (isWordByte(text, m[0]-1) || isWordByte(text, m[1])) {
+			continue
+		}
+
+		buf.WriteString(text[last:m[0]])
+		delims = append(delims, cut{pos: buf.Len(), text: opening, open: true})
+		buf.WriteString(text[m[4]:m[5]])
+		delims = append(delims, cut{pos: buf.Len(), text: closing})
+		last = m[1]
+	}
+	buf.WriteString(text[last:])
+
+	return buf.String(), delims
+}
+
+// isWordByte reports whether text[i] is an ASCII letter or digit.
+func isWordByte(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return false
+	}
+	c := text[i]
+
+	return c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// applyMarkdown runs the rules of s on text like applyRules, but keeps
+// Markdown emphasis delimiters out of the way.
+func (s *Spacer) applyMarkdown(text string, stats map[string]int) string {
+	if !strings.ContainsAny(text, "*_") {
+		return s.applyRules(text, stats)
+	}
+
+	compileOnce.Do(compile)
+
+	stripped, delims := stripEmphasis(text)
+	if len(delims) == 0 {
+		return s.applyRules(text, stats)
+	}
+
+	return reinsert(stripped, s.applyRules(stripped, stats), delims)
+}
@@ -0,0 +1,38 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+)
+
+func (suite *PanguTestSuite) TestMarkdownEmphasis() {
+	s, err := pangu.NewSpacer(pangu.Options{Markdown: true})
+	suite.Nil(err)
+
+	// bold
+	suite.Equal(pangu.SpacingText(`**重点**important`), `** 重点 **important`)
+	suite.Equal(s.SpacingText(`**重点**important`), `**重点** important`)
+	suite.Equal(s.SpacingText(`这是**important**内容`), `这是 **important** 内容`)
+	suite.Equal(s.SpacingText(`这是__bold__内容`), `这是 __bold__ 内容`)
+	suite.Equal(s.SpacingText(`**重点important**`), `**重点 important**`)
+
+	// italic
+	suite.Equal(s.SpacingText(`*斜体*text`), `*斜体* text`)
+	suite.Equal(s.SpacingText(`text*斜体*`), `text *斜体*`)
+	suite.Equal(s.SpacingText(`这是*italic*内容`), `这是 *italic* 内容`)
+	suite.Equal(s.SpacingText(`这是_italic_内容`), `这是 _italic_ 内容`)
+
+	// both
+	suite.Equal(s.SpacingText(`这是***both***内容`), `这是 ***both*** 内容`)
+
+	// already spaced
+	suite.Equal(s.SpacingText(`**重点** important`), `**重点** important`)
+}
+
+func (suite *PanguTestSuite) TestMarkdownNotEmphasis() {
+	s, err := pangu.NewSpacer(pangu.Options{Markdown: true})
+	suite.Nil(err)
+
+	suite.Equal(s.SpacingText(`变量snake_case_name很长`), `变量 snake_case_name 很长`)
+	suite.Equal(s.SpacingText(`前面*後面`), `前面 * 後面`)
+	suite.Equal(s.SpacingText(`3*4=12的结果`), `3*4=12 的结果`)
+}
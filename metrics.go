@@ -0,0 +1,101 @@
+package pangu
+
+import (
+	"sync"
+	"time"
+)
+
+// Metrics accumulates what the Spacers created with it in
+// Options.Metrics do, for profiling runs over large corpora. The zero
+// value is ready to use, and a Metrics is safe for concurrent use.
+type Metrics struct {
+	mu         sync.Mutex
+	calls      int
+	insertions int
+	elapsed    time.Duration
+	runs       map[string]int
+	rules      map[string]int
+}
+
+// Calls returns the number of texts run through the rules. The quoted
+// strings spaced within a text for Options.SpaceCallStrings are part of
+// it, and answers from the cache of a Spacer aren't counted.
+func (m *Metrics) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls
+}
+
+// Insertions returns the number of places where whitespace was added.
+func (m *Metrics) Insertions() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.insertions
+}
+
+// Elapsed returns the total time spent running the rules.
+func (m *Metrics) Elapsed() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.elapsed
+}
+
+// Runs returns the number of times each rule was run, by name. Rules
+// are run once for each line of a text, and once more for each quoted
+// string spaced by Options.SpaceCallStrings. Lines shorter than two
+// bytes, and those taken for code, are not run through the rules.
+func (m *Metrics) Runs() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return copyCounts(m.runs)
+}
+
+// Rules returns the number of changes made by each rule, by name.
+func (m *Metrics) Rules() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return copyCounts(m.rules)
+}
+
+func copyCounts(counts map[string]int) map[string]int {
+	c := make(map[string]int, len(counts))
+	for name, n := range counts {
+		c[name] = n
+	}
+
+	return c
+}
+
+func (m *Metrics) run(rules []rule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.runs == nil {
+		m.runs = map[string]int{}
+	}
+
+	for _, r := range rules {
+		m.runs[r.name]++
+	}
+}
+
+func (m *Metrics) add(elapsed time.Duration, rules map[string]int, insertions int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.rules == nil {
+		m.rules = map[string]int{}
+	}
+
+	m.calls++
+	m.insertions += insertions
+	m.elapsed += elapsed
+	for name, n := range rules {
+		m.rules[name] += n
+	}
+}
@@ -0,0 +1,53 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+)
+
+func (suite *PanguTestSuite) TestMetrics() {
+	var metrics pangu.Metrics
+	suite.Equal(metrics.Calls(), 0)
+	suite.Equal(metrics.Rules(), map[string]int{})
+	suite.Equal(metrics.Runs(), map[string]int{})
+
+	s, err := pangu.NewSpacer(pangu.Options{Metrics: &metrics})
+	suite.Nil(err)
+
+	s.SpacingText(`當你凝視著bug，bug也凝視著你`)
+	s.SpacingText(`前面(中文123漢字)後面`)
+	s.SpacingText(`已經 spaced 的文字`)
+
+	suite.Equal(metrics.Calls(), 3)
+	suite.Equal(metrics.Insertions(), 2+4)
+	suite.Equal(metrics.Rules(), map[string]int{"bracket": 2, "ans": 4})
+	suite.True(metrics.Elapsed() >= 0)
+
+	// shared by several spacers
+	other, err := pangu.NewSpacer(pangu.Options{Metrics: &metrics, CacheSize: 8})
+	suite.Nil(err)
+	other.SpacingText(`中文abc`)
+	other.SpacingText(`中文abc`)
+	suite.Equal(metrics.Calls(), 4)
+	suite.Equal(metrics.Insertions(), 7)
+}
+
+func (suite *PanguTestSuite) TestMetricsRuns() {
+	var metrics pangu.Metrics
+	s, err := pangu.NewSpacer(pangu.Options{Rules: []string{"quote", "ans"}, Metrics: &metrics})
+	suite.Nil(err)
+
+	s.SpacingText("當你凝視著bug\n也凝視著你\nx")
+	suite.Equal(metrics.Calls(), 1)
+	suite.Equal(metrics.Runs(), map[string]int{"quote": 2, "ans": 2})
+}
+
+func (suite *PanguTestSuite) TestMetricsCallStrings() {
+	var metrics pangu.Metrics
+	s, err := pangu.NewSpacer(pangu.Options{SpaceCallStrings: true, Metrics: &metrics})
+	suite.Nil(err)
+
+	suite.Equal(s.SpacingText(`调用print("中文abc")函数`), `调用 print("中文 abc") 函数`)
+	suite.Equal(metrics.Calls(), 1)
+	suite.Equal(metrics.Insertions(), 3)
+	suite.Equal(metrics.Rules(), map[string]int{"ans": 2, "bracket": 1})
+}
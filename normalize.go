@@ -0,0 +1,22 @@
+package pangu
+
+import "golang.org/x/text/unicode/norm"
+
+// SpacingTextNormalize converts text to the Unicode normalization form
+// form and then performs paranoid text spacing on it.
+//
+// Normalizing comes first, so that spacing sees the characters that are
+// stored: NFKC and NFKD fold full-width forms like "ＡＰＩ" to ASCII,
+// which is then spaced against CJK like any Latin word, and they fold
+// full-width punctuation like "，" to "," as well. Spacing only inserts
+// and removes whitespace between whole characters, so the result is
+// still in form.
+func SpacingTextNormalize(text string, form norm.Form) string {
+	return defaultSpacer.SpacingTextNormalize(text, form)
+}
+
+// SpacingTextNormalize is like the package-level SpacingTextNormalize
+// but uses the rules and options of s.
+func (s *Spacer) SpacingTextNormalize(text string, form norm.Form) string {
+	return s.SpacingText(form.String(text))
+}
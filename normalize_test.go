@@ -0,0 +1,29 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+	"golang.org/x/text/unicode/norm"
+)
+
+func (suite *PanguTestSuite) TestSpacingTextNormalize() {
+	// combining marks
+	suite.Equal(pangu.SpacingTextNormalize("咖啡cafe\u0301好喝", norm.NFC), "咖啡 caf\u00e9 好喝")
+	suite.Equal(pangu.SpacingTextNormalize("咖啡caf\u00e9好喝", norm.NFD), "咖啡 cafe\u0301 好喝")
+	suite.Equal(pangu.SpacingTextNormalize("咖啡cafe\u0301好喝", norm.NFKC), "咖啡 caf\u00e9 好喝")
+	suite.Equal(pangu.SpacingTextNormalize("咖啡caf\u00e9好喝", norm.NFKD), "咖啡 cafe\u0301 好喝")
+
+	// full-width forms are only folded by NFKC and NFKD
+	suite.Equal(pangu.SpacingTextNormalize("使用ＡＰＩ開發", norm.NFC), "使用ＡＰＩ開發")
+	suite.Equal(pangu.SpacingTextNormalize("使用ＡＰＩ開發", norm.NFD), "使用ＡＰＩ開發")
+	suite.Equal(pangu.SpacingTextNormalize("使用ＡＰＩ開發", norm.NFKC), "使用 API 開發")
+	suite.Equal(pangu.SpacingTextNormalize("使用ＡＰＩ開發", norm.NFKD), "使用 API 開發")
+	suite.Equal(pangu.SpacingTextNormalize("咖啡ｃａｆ\u00e9好喝", norm.NFKD), "咖啡 cafe\u0301 好喝")
+
+	// so is full-width punctuation
+	suite.Equal(pangu.SpacingTextNormalize("中文，abc", norm.NFC), "中文，abc")
+	suite.Equal(pangu.SpacingTextNormalize("中文，abc", norm.NFKC), "中文, abc")
+
+	for _, form := range []norm.Form{norm.NFC, norm.NFD, norm.NFKC, norm.NFKD} {
+		suite.True(form.IsNormalString(pangu.SpacingTextNormalize("咖啡ｃａｆ\u00e9好喝，ＯＫ", form)))
+	}
+}
@@ -3,11 +3,13 @@ package pangu
 import (
 	"bufio"
 	"bytes"
-	"fmt"
+	"errors"
 	"io"
-	"os"
 	"regexp"
+	"sync"
 	"text/template"
+	"unicode"
+	"unicode/utf8"
 )
 
 const VERSION = "3.0.0"
@@ -41,33 +43,138 @@ const cjk = "" +
 // ANS is short for Alphabets, Numbers
 // and Symbols (`~!@#$%^&*()-_=+[]{}\|;:'",<.>/?).
 //
-// The constant ans doesn't contain all symbols above.
-const ans = "A-Za-z0-9`\\$%\\^&\\*\\-=\\+\\\\|/\u00a1-\u00ff\u2022\u2027\u2150-\u218f"
+// The constant ans doesn't contain all symbols above, but does contain
+// Latin-1 letters and symbols, the Greek letters used by units like
+// µm and Ω, currency symbols like € and ₩, and enclosed alphanumerics
+// like ① and Ⓐ. The middle dot is left out, since it joins names like
+// 约翰·史密斯 as often as it separates Latin items like A·B·C.
+const ans = "A-Za-z0-9`\\$%\\^&\\*\\-=\\+\\\\|/\u00a1-\u00b6\u00b8-\u00ff\u0370-\u03ff\u2011\u2022\u2027\u20a0-\u20cf\u2150-\u218f\u2460-\u24ff"
 
-var cjk_quote = regexp.MustCompile(re("([{{ .CJK }}])" + "([\"'])"))
-var quote_cjk = regexp.MustCompile(re("([\"'])" + "([{{ .CJK }}])"))
-var fix_quote = regexp.MustCompile(re("([\"'\\(\\[\\{<\u201c])" + "(\\s*)" + "(.+?)" + "(\\s*)" + "([\"'\\)\\]\\}>\u201d])"))
-var fix_single_quote = regexp.MustCompile(re("([{{ .CJK }}])" + "( )" + "(')" + "([A-Za-z])"))
+// cjkTable holds the same ranges as cjk.
+var cjkTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x2e80, 0x2eff, 1},
+		{0x2f00, 0x2fdf, 1},
+		{0x3040, 0x309f, 1},
+		{0x30a0, 0x30ff, 1},
+		{0x3100, 0x312f, 1},
+		{0x3200, 0x32ff, 1},
+		{0x3400, 0x4dbf, 1},
+		{0x4e00, 0x9fff, 1},
+		{0xf900, 0xfaff, 1},
+	},
+}
+
+// ansTable holds the same runes as ans.
+var ansTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0024, 0x0026, 1}, // $%&
+		{0x002a, 0x002b, 1}, // *+
+		{0x002d, 0x002d, 1}, // -
+		{0x002f, 0x0039, 1}, // / and 0-9
+		{0x003d, 0x003d, 1}, // =
+		{0x0041, 0x005a, 1}, // A-Z
+		{0x005c, 0x005c, 1}, // \
+		{0x005e, 0x005e, 1}, // ^
+		{0x0060, 0x007a, 1}, // ` and a-z
+		{0x007c, 0x007c, 1}, // |
+		{0x00a1, 0x00b6, 1},
+		{0x00b8, 0x00ff, 1},
+		{0x0370, 0x03ff, 1},
+		{0x2011, 0x2011, 1},
+		{0x2022, 0x2022, 1},
+		{0x2027, 0x2027, 1},
+		{0x20a0, 0x20cf, 1},
+		{0x2150, 0x218f, 1},
+		{0x2460, 0x24ff, 1},
+	},
+	LatinOffset: 12,
+}
+
+// CJKRangeTable returns the Unicode ranges treated as CJK characters,
+// for use with unicode.Is. It returns a copy, so changing it doesn't
+// change how text is spaced.
+func CJKRangeTable() *unicode.RangeTable {
+	return copyTable(cjkTable)
+}
+
+// ANSRangeTable returns the Unicode ranges treated as half-width
+// alphabets, numbers and symbols, for use with unicode.Is.
+// It returns a copy, like CJKRangeTable.
+func ANSRangeTable() *unicode.RangeTable {
+	return copyTable(ansTable)
+}
+
+func copyTable(t *unicode.RangeTable) *unicode.RangeTable {
+	return &unicode.RangeTable{
+		R16:         append([]unicode.Range16(nil), t.R16...),
+		R32:         append([]unicode.Range32(nil), t.R32...),
+		LatinOffset: t.LatinOffset,
+	}
+}
+
+// IGN is short for ignorable: invisible or combining runes that sit
+// between a CJK character and a half-width one without breaking their
+// adjacency.
+//
+// The constant ign contains:
+// 	\u0300-\u036f Combining Diacritical Marks
+// 	\u200d Zero Width Joiner
+// 	\u20d0-\u20ff Combining Diacritical Marks for Symbols
+// 	\ufe00-\ufe0f Variation Selectors
+// 	\U000e0100-\U000e01ef Variation Selectors Supplement
+//
+// The Word Joiner \u2060 is left out on purpose: it tells that no break
+// is wanted, so it keeps a space from being inserted instead.
+const ign = "\u0300-\u036f\u200d\u20d0-\u20ff\ufe00-\ufe0f\U000e0100-\U000e01ef"
+
+// ignTable holds the same ranges as ign.
+var ignTable = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x0300, 0x036f, 1},
+		{0x200d, 0x200d, 1},
+		{0x20d0, 0x20ff, 1},
+		{0xfe00, 0xfe0f, 1},
+	},
+	R32: []unicode.Range32{
+		{0xe0100, 0xe01ef, 1},
+	},
+}
+
+// The rule regexps, and those finding the spans to protect from the
+// rules, are compiled on first use by compile, so that programs that
+// import pangu without calling it don't pay for them.
+var (
+	cjk_quote, quote_cjk, fix_quote, fix_single_quote *regexp.Regexp
+
+	cjk_hash, hash_cjk *regexp.Regexp
 
-var cjk_hash = regexp.MustCompile(re("([{{ .CJK }}])" + "(#(\\S+))"))
-var hash_cjk = regexp.MustCompile(re("((\\S+)#)" + "([{{ .CJK }}])"))
+	cjk_operator_ans, ans_operator_cjk *regexp.Regexp
 
-var cjk_operator_ans = regexp.MustCompile(re("([{{ .CJK }}])" + "([\\+\\-\\*/=&\\|<>])" + "([A-Za-z0-9])"))
-var ans_operator_cjk = regexp.MustCompile(re("([A-Za-z0-9])" + "([\\+\\-\\*/=&\\|<>])" + "([{{ .CJK }}])"))
+	cjk_bracket_cjk, cjk_bracket, bracket_cjk, cjk_open_bracket, close_bracket_cjk, fix_bracket *regexp.Regexp
 
-var cjk_bracket_cjk = regexp.MustCompile(re("([{{ .CJK }}])" + "([\\(\\[\\{<\u201c]+(.*?)[\\)\\]\\}>\u201d]+)" + "([{{ .CJK }}])"))
-var cjk_bracket = regexp.MustCompile(re("([{{ .CJK }}])" + "([\\(\\[\\{<\u201c>])"))
-var bracket_cjk = regexp.MustCompile(re("([\\)\\]\\}>\u201d<])" + "([{{ .CJK }}])"))
-var fix_bracket = regexp.MustCompile(re("([\\(\\[\\{<\u201c]+)" + "(\\s*)" + "(.+?)" + "(\\s*)" + "([\\)\\]\\}>\u201d]+)"))
+	fix_symbol, fix_comma, fix_comma_cjk *regexp.Regexp
+
+	cjk_tab_ans, ans_tab_cjk *regexp.Regexp
+
+	space_fullwidth_close, fullwidth_open_space, cjk_spaces_ans, ans_spaces_cjk *regexp.Regexp
+
+	cjk_ans, ans_cjk *regexp.Regexp
+
+	call_open, quoted_string, placeholder, emphasis, keycap, symbol *regexp.Regexp
+)
 
-var fix_symbol = regexp.MustCompile(re("([{{ .CJK }}])" + "([~!;:,\\.\\?\u2026])" + "([A-Za-z0-9])"))
+// protected holds the built-in regexps of the spans protected like those
+// of Options.Protect. It is set by compile.
+var protected []*regexp.Regexp
 
-var cjk_ans = regexp.MustCompile(re("([{{ .CJK }}])([{{ .ANS }}@])"))
-var ans_cjk = regexp.MustCompile(re("([{{ .ANS }}~!;:,\\.\\?\u2026])([{{ .CJK }}])"))
+var compileOnce sync.Once
 
 var context = map[string]string{
-	"CJK": cjk,
-	"ANS": ans,
+	"CJK":  cjk,
+	"ANS":  ans + string(atom),
+	"IGN":  ign,
+	"ATOM": string(atom),
 }
 
 func re(exp string) string {
@@ -81,24 +188,90 @@ func re(exp string) string {
 	return expr
 }
 
-// SpacingText performs paranoid text spacing on text.
-// It returns the processed text, with love.
-func SpacingText(text string) string {
-	if len(text) < 2 {
-		return text
-	}
+// compile builds the rule and protection regexps. It is run once, through
+// compileOnce, by the first call that needs them.
+func compile() {
+	cjk_quote = regexp.MustCompile(re("([{{ .CJK }}])" + "([\"'])"))
+	quote_cjk = regexp.MustCompile(re("([\"'])" + "([{{ .CJK }}])"))
+	fix_quote = regexp.MustCompile(re("([\"'\\(\\[\\{<\u201c])" + "(\\s*)" + "(.+?)" + "(\\s*)" + "([\"'\\)\\]\\}>\u201d])"))
+	fix_single_quote = regexp.MustCompile(re("([{{ .CJK }}])" + "( )" + "(')" + "([A-Za-z])"))
+
+	cjk_hash = regexp.MustCompile(re("([{{ .CJK }}])" + "(#(\\S+))"))
+	hash_cjk = regexp.MustCompile(re("((\\S+)#)" + "([{{ .CJK }}])"))
+
+	cjk_operator_ans = regexp.MustCompile(re("([{{ .CJK }}])" + "([\\+\\-\\*/=&\\|<>])" + "([A-Za-z0-9{{ .ATOM }}])"))
+	ans_operator_cjk = regexp.MustCompile(re("([A-Za-z0-9{{ .ATOM }}])" + "([\\+\\-\\*/=&\\|<>])" + "([{{ .CJK }}])"))
+
+	cjk_bracket_cjk = regexp.MustCompile(re("([{{ .CJK }}])" + "([\\(\\[\\{<\u201c]+(.*?)[\\)\\]\\}>\u201d]+)" + "([{{ .CJK }}])"))
+	cjk_bracket = regexp.MustCompile(re("([{{ .CJK }}])" + "([\\(\\[\\{<\u201c>])"))
+	bracket_cjk = regexp.MustCompile(re("([\\)\\]\\}>\u201d<])" + "([{{ .CJK }}])"))
+	cjk_open_bracket = regexp.MustCompile(re("([{{ .CJK }}])" + "([\\(\\[\\{\u201c])"))
+	close_bracket_cjk = regexp.MustCompile(re("([\\)\\]\\}\u201d])" + "([{{ .CJK }}])"))
+	fix_bracket = regexp.MustCompile(re("([\\(\\[\\{<\u201c]+)" + "(\\s*)" + "(.+?)" + "(\\s*)" + "([\\)\\]\\}>\u201d]+)"))
+
+	fix_symbol = regexp.MustCompile(re("([{{ .CJK }}])" + "([~!;:,\\.\\?\u2026])" + "([A-Za-z0-9])"))
+	fix_comma = regexp.MustCompile(re("([{{ .CJK }}])" + " *(,) *" + "([A-Za-z0-9])"))
+	fix_comma_cjk = regexp.MustCompile(re("([A-Za-z0-9])" + " *(,) *" + "([{{ .CJK }}])"))
+
+	cjk_tab_ans = regexp.MustCompile(re("([{{ .CJK }}])\t([{{ .ANS }}@])"))
+	ans_tab_cjk = regexp.MustCompile(re("([{{ .ANS }}])\t([{{ .CJK }}])"))
+
+	space_fullwidth_close = regexp.MustCompile(re("([^\\s])[ \t]+([\uff0c\u3002\uff01\uff1f\uff1b\uff1a\u3001\uff09\u300d\u300f\u3011\u300b\u3009\u3015\u3017])"))
+	fullwidth_open_space = regexp.MustCompile(re("([\uff08\u300c\u300e\u3010\u300a\u3008\u3014\u3016])[ \t]+"))
+	cjk_spaces_ans = regexp.MustCompile(re("([{{ .CJK }}]) {2,}([{{ .ANS }}])"))
+	ans_spaces_cjk = regexp.MustCompile(re("([{{ .ANS }}]) {2,}([{{ .CJK }}])"))
+
+	cjk_ans = regexp.MustCompile(re("([{{ .CJK }}][{{ .IGN }}]*)([{{ .ANS }}@])"))
+	ans_cjk = regexp.MustCompile(re("([{{ .ANS }}~!;:,\\.\\?\u2026][{{ .IGN }}]*)([{{ .CJK }}])"))
+
+	call_open = regexp.MustCompile("[A-Za-z_][A-Za-z0-9_.]*\\(")
+	quoted_string = regexp.MustCompile("\"[^\"]*\"|'[^']*'")
 
+	// placeholder matches angle-bracketed placeholders like <username>,
+	// which are spaced like a single word rather than as comparisons.
+	placeholder = regexp.MustCompile("<[A-Za-z_][A-Za-z0-9_\\-]*>")
+
+	// emphasis matches a Markdown emphasis span, like **bold** or _italic_.
+	// The opening and closing delimiters must be the same, which is checked
+	// by stripEmphasis since regexp has no backreferences.
+	emphasis = regexp.MustCompile("(\\*\\*\\*|\\*\\*|\\*|___|__|_)([^\\s*_](?:[^*_\\n]*[^\\s*_])?)(\\*\\*\\*|\\*\\*|\\*|___|__|_)")
+
+	// keycap matches emoji keycap sequences, a digit, "#" or "*" followed
+	// by an optional variation selector and the combining enclosing
+	// keycap, which are spaced as a single character.
+	keycap = regexp.MustCompile("[0-9#*]\ufe0f?\u20e3")
+
+	// symbol matches a symbol of the Miscellaneous Symbols or Dingbats
+	// blocks, with its variation selector if any, for Options.SpaceSymbols.
+	symbol = regexp.MustCompile("[\u2600-\u27bf]\ufe0f?")
+
+	protected = []*regexp.Regexp{placeholder, keycap}
+}
+
+func spacingQuote(text string) string {
 	text = cjk_quote.ReplaceAllString(text, "$1 $2")
 	text = quote_cjk.ReplaceAllString(text, "$1 $2")
 	text = fix_quote.ReplaceAllString(text, "$1$3$5")
 	text = fix_single_quote.ReplaceAllString(text, "$1$3$4")
 
+	return text
+}
+
+func spacingHash(text string) string {
 	text = cjk_hash.ReplaceAllString(text, "$1 $2")
 	text = hash_cjk.ReplaceAllString(text, "$1 $3")
 
+	return text
+}
+
+func spacingOperator(text string) string {
 	text = cjk_operator_ans.ReplaceAllString(text, "$1 $2 $3")
 	text = ans_operator_cjk.ReplaceAllString(text, "$1 $2 $3")
 
+	return text
+}
+
+func spacingBracket(text string) string {
 	oldText := text
 	newText := cjk_bracket_cjk.ReplaceAllString(oldText, "$1 $2 $4")
 	text = newText
@@ -106,42 +279,141 @@ func SpacingText(text string) string {
 		text = cjk_bracket.ReplaceAllString(text, "$1 $2")
 		text = bracket_cjk.ReplaceAllString(text, "$1 $2")
 	}
+	// Brackets nested inside brackets, e.g. reference markers.
+	text = cjk_open_bracket.ReplaceAllString(text, "$1 $2")
+	text = close_bracket_cjk.ReplaceAllString(text, "$1 $2")
 	text = fix_bracket.ReplaceAllString(text, "$1$3$5")
 
+	return text
+}
+
+func spacingSymbol(text string) string {
 	text = fix_symbol.ReplaceAllString(text, "$1$2 $3")
+	text = fix_comma.ReplaceAllString(text, "$1$2 $3")
+	text = fix_comma_cjk.ReplaceAllString(text, "$1$2 $3")
 
+	return text
+}
+
+func spacingANS(text string) string {
 	text = cjk_ans.ReplaceAllString(text, "$1 $2")
 	text = ans_cjk.ReplaceAllString(text, "$1 $2")
 
 	return text
 }
 
+func spacingTab(text string) string {
+	text = cjk_tab_ans.ReplaceAllString(text, "$1 $2")
+	text = ans_tab_cjk.ReplaceAllString(text, "$1 $2")
+
+	return text
+}
+
+func spacingNormalize(text string) string {
+	text = space_fullwidth_close.ReplaceAllString(text, "$1$2")
+	text = fullwidth_open_space.ReplaceAllString(text, "$1")
+	text = cjk_spaces_ans.ReplaceAllString(text, "$1 $2")
+	text = ans_spaces_cjk.ReplaceAllString(text, "$1 $2")
+
+	return text
+}
+
+// rule is a named step of the spacing pipeline.
+type rule struct {
+	name  string
+	apply func(text string) string
+}
+
+// rules holds every known rule, in the default order.
+var rules = []rule{
+	{"quote", spacingQuote},
+	{"hash", spacingHash},
+	{"operator", spacingOperator},
+	{"bracket", spacingBracket},
+	{"symbol", spacingSymbol},
+	{"ans", spacingANS},
+}
+
+// DefaultRules returns the names of the rules run by SpacingText,
+// in order.
+func DefaultRules() []string {
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = r.name
+	}
+
+	return names
+}
+
+// SpacingText performs paranoid text spacing on text.
+// Each line is spaced on its own, so that no rule reaches across a line
+// break. It returns the processed text, with love.
+func SpacingText(text string) string {
+	return defaultSpacer.SpacingText(text)
+}
+
+// SpacingArgs performs paranoid text spacing on each of args, such as
+// command-line arguments, and returns the results in a new slice.
+func SpacingArgs(args []string) []string {
+	return defaultSpacer.SpacingArgs(args)
+}
+
 // SpacingFile reads the file named by filename, performs paranoid text
 // spacing on its contents and writes the processed content to w.
 // A successful call returns err == nil.
 func SpacingFile(filename string, w io.Writer) (err error) {
-	fr, err := os.Open(filename)
-	if err != nil {
+	return defaultSpacer.SpacingFile(filename, w)
+}
+
+// ErrBinaryFile is returned when a file doesn't look like UTF-8 text.
+var ErrBinaryFile = errors.New("pangu: binary file")
+
+// ErrUnsupportedEncoding is returned when a file starts with a UTF-16 or
+// UTF-32 byte order mark. Only UTF-8 (with or without BOM) is supported.
+var ErrUnsupportedEncoding = errors.New("pangu: unsupported encoding")
+
+// sniffLen is how many leading bytes of a file are inspected by sniff.
+const sniffLen = 512
+
+// sniff peeks at the beginning of br and reports whether it looks like
+// UTF-8 text.
+func sniff(br *bufio.Reader) error {
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
 		return err
 	}
-	defer fr.Close()
-
-	br := bufio.NewReader(fr)
-	bw := bufio.NewWriter(w)
-
-	for {
-		line, err := br.ReadString('\n')
-		if err == nil {
-			fmt.Fprint(bw, SpacingText(line))
-		} else {
-			if err == io.EOF {
-				fmt.Fprint(bw, SpacingText(line))
+
+	// UTF-16 (and UTF-32 LE) BOMs, plus the UTF-32 BE one.
+	if bytes.HasPrefix(head, []byte{0xfe, 0xff}) ||
+		bytes.HasPrefix(head, []byte{0xff, 0xfe}) ||
+		bytes.HasPrefix(head, []byte{0x00, 0x00, 0xfe, 0xff}) {
+		return ErrUnsupportedEncoding
+	}
+
+	if bytes.IndexByte(head, 0x00) != -1 {
+		return ErrBinaryFile
+	}
+
+	// The last rune may have been cut in half by Peek.
+	for i := 0; i < len(head); {
+		r, size := utf8.DecodeRune(head[i:])
+		if r == utf8.RuneError && size == 1 {
+			if len(head)-i < utf8.UTFMax && !utf8.FullRune(head[i:]) {
 				break
 			}
-			return err
+			return ErrBinaryFile
 		}
+		i += size
 	}
-	defer bw.Flush()
 
 	return nil
 }
+
+// IsFileSpaced reports whether the file named by filename is already
+// fully spaced, that is, whether SpacingFile would leave it unchanged.
+// It stops reading at the first line that needs spacing.
+// Files that don't look like UTF-8 text yield ErrBinaryFile or
+// ErrUnsupportedEncoding.
+func IsFileSpaced(filename string) (bool, error) {
+	return defaultSpacer.IsFileSpaced(filename)
+}
@@ -9,7 +9,9 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
+	"unicode"
 )
 
 type PanguTestSuite struct {
@@ -52,21 +54,63 @@ func (suite *PanguTestSuite) TestSpacingText() {
 	suite.Equal(pangu.SpacingText(`V`), `V`)
 }
 
+func (suite *PanguTestSuite) TestSingleLetter() {
+	suite.Equal(pangu.SpacingText(`设x为5`), `设 x 为 5`)
+	suite.Equal(pangu.SpacingText(`设x`), `设 x`)
+	suite.Equal(pangu.SpacingText(`x是变量`), `x 是变量`)
+	// not 点 A 到 点 B: pangu never spaces between two CJK characters
+	suite.Equal(pangu.SpacingText(`点A到点B`), `点 A 到点 B`)
+	suite.Equal(pangu.SpacingText(`向量v和向量w`), `向量 v 和向量 w`)
+	suite.Equal(pangu.SpacingText(`令a、b、c为`), `令 a、b、c 为`)
+	suite.Equal(pangu.SpacingText(`设 x 为 5`), `设 x 为 5`)
+	suite.Equal(pangu.SpacingText(`点 A 到点 B`), `点 A 到点 B`)
+}
+
 func (suite *PanguTestSuite) TestLatin1Supplement() {
 	suite.Equal(pangu.SpacingText(`中文Ø漢字`), `中文 Ø 漢字`)
 	suite.Equal(pangu.SpacingText(`中文 Ø 漢字`), `中文 Ø 漢字`)
 }
 
+func (suite *PanguTestSuite) TestGreekAndCoptic() {
+	suite.Equal(pangu.SpacingText(`电阻10Ω很大`), `电阻 10Ω 很大`)
+	suite.Equal(pangu.SpacingText(`直径5µm的颗粒`), `直径 5µm 的颗粒`)
+	suite.Equal(pangu.SpacingText(`直径5μm的颗粒`), `直径 5μm 的颗粒`) // μ is U+03BC
+	suite.Equal(pangu.SpacingText(`相位差30°，约π/6弧度`), `相位差 30°，约 π/6 弧度`)
+	suite.Equal(pangu.SpacingText(`温度变化ΔT很小`), `温度变化 ΔT 很小`)
+	suite.Equal(pangu.SpacingText(`电阻 10Ω 很大`), `电阻 10Ω 很大`)
+}
+
 func (suite *PanguTestSuite) TestGeneralPunctuation() {
 	suite.Equal(pangu.SpacingText(`中文•漢字`), `中文 • 漢字`)
 	suite.Equal(pangu.SpacingText(`中文 • 漢字`), `中文 • 漢字`)
 }
 
+func (suite *PanguTestSuite) TestCurrencySymbols() {
+	suite.Equal(pangu.SpacingText(`花费¥100买`), `花费 ¥100 买`)
+	suite.Equal(pangu.SpacingText(`价格€50贵`), `价格 €50 贵`)
+	suite.Equal(pangu.SpacingText(`只要£20而已`), `只要 £20 而已`)
+	suite.Equal(pangu.SpacingText(`售价₩5000起`), `售价 ₩5000 起`)
+	suite.Equal(pangu.SpacingText(`价格€50，很贵`), `价格 €50，很贵`)
+	suite.Equal(pangu.SpacingText(`价格 €50 贵`), `价格 €50 贵`)
+}
+
 func (suite *PanguTestSuite) TestNumberForms() {
 	suite.Equal(pangu.SpacingText(`中文Ⅶ漢字`), `中文 Ⅶ 漢字`)
 	suite.Equal(pangu.SpacingText(`中文 Ⅶ 漢字`), `中文 Ⅶ 漢字`)
 }
 
+func (suite *PanguTestSuite) TestEnclosedAlphanumerics() {
+	suite.Equal(pangu.SpacingText(`第①步start`), `第 ① 步 start`)
+	suite.Equal(pangu.SpacingText(`步骤①和②`), `步骤 ① 和 ②`)
+	suite.Equal(pangu.SpacingText(`选项Ⓐ正确`), `选项 Ⓐ 正确`)
+	suite.Equal(pangu.SpacingText(`见⑴abc`), `见 ⑴abc`)
+	suite.Equal(pangu.SpacingText(`第 ① 步 start`), `第 ① 步 start`)
+
+	// a combining enclosing circle stays with its digit
+	suite.Equal(pangu.SpacingText("第1\u20dd步"), "第 1\u20dd 步")
+	suite.Equal(pangu.SpacingText("第A\u20dd步"), "第 A\u20dd 步")
+}
+
 func (suite *PanguTestSuite) TestCJKRadicalsSupplement() {
 	suite.Equal(pangu.SpacingText(`abc⻤123`), `abc ⻤ 123`)
 	suite.Equal(pangu.SpacingText(`abc ⻤ 123`), `abc ⻤ 123`)
@@ -112,6 +156,67 @@ func (suite *PanguTestSuite) TestCJKCompatibilityIdeographs() {
 	suite.Equal(pangu.SpacingText(`abc 車 123`), `abc 車 123`)
 }
 
+func (suite *PanguTestSuite) TestZeroWidthJoiner() {
+	// a stray \u200d doesn't break the adjacency of CJK and Latin
+	suite.Equal(pangu.SpacingText("中文\u200dEnglish"), "中文\u200d English")
+	suite.Equal(pangu.SpacingText("English\u200d中文"), "English\u200d 中文")
+	suite.Equal(pangu.SpacingText("中文\u200d English"), "中文\u200d English")
+
+	// so doesn't a combining mark
+	suite.Equal(pangu.SpacingText("咖啡cafe\u0301好喝"), "咖啡 cafe\u0301 好喝")
+
+	// nor does a variation selector
+	suite.Equal(pangu.SpacingText("字\ufe00text"), "字\ufe00 text")
+	suite.Equal(pangu.SpacingText("葛\U000e0100city"), "葛\U000e0100 city")
+	suite.Equal(pangu.SpacingText("text葛\U000e0101"), "text 葛\U000e0101")
+	suite.Equal(pangu.SpacingText("text\ufe0e字"), "text\ufe0e 字")
+	suite.Equal(pangu.SpacingText("字\ufe00 text"), "字\ufe00 text")
+
+	// emoji ZWJ sequences are left alone
+	suite.Equal(pangu.SpacingText("我是👩\u200d💻工程师"), "我是👩\u200d💻工程师")
+	suite.Equal(pangu.SpacingText("中文👨\u200d👩\u200d👧English"), "中文👨\u200d👩\u200d👧English")
+}
+
+func (suite *PanguTestSuite) TestWordJoiner() {
+	// \u2060 between CJK and Latin means no break is wanted
+	suite.Equal(pangu.SpacingText("中文\u2060abc"), "中文\u2060abc")
+	suite.Equal(pangu.SpacingText("abc\u2060中文"), "abc\u2060中文")
+	suite.Equal(pangu.SpacingText("中文\u2060abc中文"), "中文\u2060abc 中文")
+	suite.Equal(pangu.SpacingText("中文\u2060 abc"), "中文\u2060 abc")
+
+	skip, err := pangu.NewSpacer(pangu.Options{SkipControls: true})
+	suite.Nil(err)
+	suite.Equal(skip.SpacingText("中文\u2060abc"), "中文\u2060abc")
+}
+
+func (suite *PanguTestSuite) TestControlCharacters() {
+	// control characters are kept and separate what is around them
+	suite.Equal(pangu.SpacingText("中文\x00abc"), "中文\x00abc")
+	suite.Equal(pangu.SpacingText("abc\x01中文"), "abc\x01中文")
+	suite.Equal(pangu.SpacingText("\x00中文abc\x7f"), "\x00中文 abc\x7f")
+	suite.Equal(pangu.SpacingText("中\x00文"), "中\x00文")
+}
+
+func (suite *PanguTestSuite) TestBidiControls() {
+	// embeddings and overrides, closed by PDF
+	suite.Equal(pangu.SpacingText("中文\u202aabc\u202c中文"), "中文 \u202aabc\u202c 中文")
+	suite.Equal(pangu.SpacingText("中文\u202babc\u202c中文"), "中文 \u202babc\u202c 中文")
+	suite.Equal(pangu.SpacingText("中文\u202dabc\u202c中文"), "中文 \u202dabc\u202c 中文")
+	suite.Equal(pangu.SpacingText("中文\u202eabc\u202c中文"), "中文 \u202eabc\u202c 中文")
+
+	// isolates, closed by PDI
+	suite.Equal(pangu.SpacingText("中文\u2066abc\u2069中文"), "中文 \u2066abc\u2069 中文")
+	suite.Equal(pangu.SpacingText("中文\u2067abc\u2069中文"), "中文 \u2067abc\u2069 中文")
+	suite.Equal(pangu.SpacingText("中文\u2068abc\u2069中文"), "中文 \u2068abc\u2069 中文")
+
+	suite.Equal(pangu.SpacingText("abc\u202b中文\u202c"), "abc \u202b中文\u202c")
+	suite.Equal(pangu.SpacingText("中文\u202a中文\u202c"), "中文\u202a中文\u202c")
+	suite.Equal(pangu.SpacingText("中文 \u202aabc\u202c 中文"), "中文 \u202aabc\u202c 中文")
+
+	// the space goes between a closer and the next opener
+	suite.Equal(pangu.SpacingText("\u202b中文\u202c\u202aabc\u202c"), "\u202b中文\u202c \u202aabc\u202c")
+}
+
 func (suite *PanguTestSuite) TestTilde() {
 	suite.Equal(pangu.SpacingText(`前面~後面`), `前面~ 後面`)
 	suite.Equal(pangu.SpacingText(`前面 ~ 後面`), `前面 ~ 後面`)
@@ -224,6 +329,96 @@ func (suite *PanguTestSuite) TestMinus() {
 	suite.Equal(pangu.SpacingText(`得到一個A-B的結果`), `得到一個 A-B 的結果`)
 }
 
+func (suite *PanguTestSuite) TestISODate() {
+	suite.Equal(pangu.SpacingText(`2024-01-02发布`), `2024-01-02 发布`)
+	suite.Equal(pangu.SpacingText(`于2024-01-02发布`), `于 2024-01-02 发布`)
+	suite.Equal(pangu.SpacingText(`从2024-01-02到2024-02-01`), `从 2024-01-02 到 2024-02-01`)
+	suite.Equal(pangu.SpacingText(`(2024-01-02)发布`), `(2024-01-02) 发布`)
+
+	// date-times
+	suite.Equal(pangu.SpacingText(`于2024-01-02T10:00:00Z发布`), `于 2024-01-02T10:00:00Z 发布`)
+	suite.Equal(pangu.SpacingText(`时间2024-01-02T10:00:00+08:00结束`), `时间 2024-01-02T10:00:00+08:00 结束`)
+	suite.Equal(pangu.SpacingText(`时间2024-01-02T10:00:00.123-0500结束`), `时间 2024-01-02T10:00:00.123-0500 结束`)
+	suite.Equal(pangu.SpacingText(`于 2024-01-02T10:00:00Z 发布`), `于 2024-01-02T10:00:00Z 发布`)
+}
+
+func (suite *PanguTestSuite) TestInlineLiterals() {
+	// arrays
+	suite.Equal(pangu.SpacingText(`返回[1,2,3]数组`), `返回 [1,2,3] 数组`)
+	suite.Equal(pangu.SpacingText(`返回[1, 2, 3]数组`), `返回 [1, 2, 3] 数组`)
+	suite.Equal(pangu.SpacingText(`返回["你好","世界"]数组`), `返回 ["你好","世界"] 数组`)
+	suite.Equal(pangu.SpacingText(`数组[1,2,3]。`), `数组 [1,2,3]。`)
+
+	// objects
+	suite.Equal(pangu.SpacingText(`对象{a:1}结构`), `对象 {a:1} 结构`)
+	suite.Equal(pangu.SpacingText(`对象{"键":"值"}结构`), `对象 {"键":"值"} 结构`)
+	suite.Equal(pangu.SpacingText(`对象{a: "x", b: 2}结构`), `对象 {a: "x", b: 2} 结构`)
+
+	// nested
+	suite.Equal(pangu.SpacingText(`返回[[1,2],[3]]数组`), `返回 [[1,2],[3]] 数组`)
+	suite.Equal(pangu.SpacingText(`对象{"a":{"b":[1,2]}}结构`), `对象 {"a":{"b":[1,2]}} 结构`)
+	suite.Equal(pangu.SpacingText(`对象{a: "x", b: [1,2]}结构`), `对象 {a: "x", b: [1,2]} 结构`)
+}
+
+func (suite *PanguTestSuite) TestSandwichedCJK() {
+	for text, expected := range map[string]string{
+		// multi-character runs
+		`aPPle苹果banana`: `aPPle 苹果 banana`,
+		`Go語言Rust`:      `Go 語言 Rust`,
+		`iPhone與iPad`:   `iPhone 與 iPad`,
+
+		// single characters
+		`a中b`:     `a 中 b`,
+		`abc中def`: `abc 中 def`,
+		`1個2`:     `1 個 2`,
+		`a中b中c`:   `a 中 b 中 c`,
+
+		// one side already spaced
+		`a 中b`: `a 中 b`,
+		`a中 b`: `a 中 b`,
+	} {
+		suite.Equal(pangu.SpacingText(text), expected)
+		suite.Equal(pangu.SpacingText(expected), expected)
+	}
+}
+
+func (suite *PanguTestSuite) TestMiddleDot() {
+	// separating Latin items
+	suite.Equal(pangu.SpacingText(`A·B·C项目`), `A·B·C 项目`)
+	suite.Equal(pangu.SpacingText(`项目A·B·C`), `项目 A·B·C`)
+	suite.Equal(pangu.SpacingText(`使用A·B·C项目`), `使用 A·B·C 项目`)
+	suite.Equal(pangu.SpacingText(`Tom·Jerry和`), `Tom·Jerry 和`)
+
+	// joining names
+	suite.Equal(pangu.SpacingText(`约翰·史密斯说`), `约翰·史密斯说`)
+	suite.Equal(pangu.SpacingText(`列奥纳多·达·芬奇`), `列奥纳多·达·芬奇`)
+	suite.Equal(pangu.SpacingText(`约翰·Smith说`), `约翰·Smith 说`)
+}
+
+func (suite *PanguTestSuite) TestKeycap() {
+	suite.Equal(pangu.SpacingText("1\ufe0f\u20e3步骤"), "1\ufe0f\u20e3 步骤")
+	suite.Equal(pangu.SpacingText("步骤1\ufe0f\u20e3开始"), "步骤 1\ufe0f\u20e3 开始")
+	suite.Equal(pangu.SpacingText("按1\ufe0f\u20e32\ufe0f\u20e3键"), "按 1\ufe0f\u20e32\ufe0f\u20e3 键")
+
+	// "#" and "*" keycaps
+	suite.Equal(pangu.SpacingText("按#\ufe0f\u20e3键"), "按 #\ufe0f\u20e3 键")
+	suite.Equal(pangu.SpacingText("按*\ufe0f\u20e3键"), "按 *\ufe0f\u20e3 键")
+
+	// without the variation selector
+	suite.Equal(pangu.SpacingText("步骤1\u20e3开始"), "步骤 1\u20e3 开始")
+
+	// the sequence stays one grapheme
+	suite.Equal(pangu.SpacingTextGraphemes("#\ufe0f\u20e3号"), []string{"#\ufe0f\u20e3", " ", "号"})
+}
+
+func (suite *PanguTestSuite) TestNonBreakingHyphen() {
+	// ‑ is \u2011
+	suite.Equal(pangu.SpacingText(`X‑ray检查`), `X‑ray 检查`)
+	suite.Equal(pangu.SpacingText(`进行X‑ray检查`), `进行 X‑ray 检查`)
+	suite.Equal(pangu.SpacingText(`进行 X‑ray 检查`), `进行 X‑ray 检查`)
+	suite.Equal(pangu.SpacingText(`使用e‑mail联系`), `使用 e‑mail 联系`)
+}
+
 func (suite *PanguTestSuite) TestUnderscore() {
 	suite.Equal(pangu.SpacingText(`前面_後面`), `前面_後面`)
 	suite.Equal(pangu.SpacingText(`前面 _ 後面`), `前面 _ 後面`)
@@ -286,6 +481,36 @@ func (suite *PanguTestSuite) TestBracket() {
 	suite.Equal(pangu.SpacingText(`head [中文123漢字] tail`), `head [中文 123 漢字] tail`)
 }
 
+func (suite *PanguTestSuite) TestNestedBrackets() {
+	// full-width brackets around half-width reference markers
+	suite.Equal(pangu.SpacingText(`注释（见[1]和[2]）如下`), `注释（见 [1] 和 [2]）如下`)
+	suite.Equal(pangu.SpacingText(`注释（见[1]）`), `注释（见 [1]）`)
+	suite.Equal(pangu.SpacingText(`（[1]见）`), `（[1] 见）`)
+	suite.Equal(pangu.SpacingText(`注释（见 [1] 和 [2]）如下`), `注释（见 [1] 和 [2]）如下`)
+
+	// half-width brackets around half-width reference markers
+	suite.Equal(pangu.SpacingText(`见[1]和[2]`), `见 [1] 和 [2]`)
+	suite.Equal(pangu.SpacingText(`注释(见[1]和[2])如下`), `注释 (见 [1] 和 [2]) 如下`)
+	suite.Equal(pangu.SpacingText(`注释 (见 [1] 和 [2]) 如下`), `注释 (见 [1] 和 [2]) 如下`)
+}
+
+func (suite *PanguTestSuite) TestFullWidthBrackets() {
+	// full-width brackets carry their own spacing, so nothing is inserted
+	// on either side of them
+	suite.Equal(pangu.SpacingText(`（Note）说明`), `（Note）说明`)
+	suite.Equal(pangu.SpacingText(`说明（Note）`), `说明（Note）`)
+	suite.Equal(pangu.SpacingText(`使用（API）接口`), `使用（API）接口`)
+	suite.Equal(pangu.SpacingText(`【Note】说明`), `【Note】说明`)
+	suite.Equal(pangu.SpacingText(`「Note」说明`), `「Note」说明`)
+
+	// but CJK and Latin inside them are spaced as usual
+	suite.Equal(pangu.SpacingText(`（Note说明）`), `（Note 说明）`)
+	suite.Equal(pangu.SpacingText(`用《Go语言》学`), `用《Go 语言》学`)
+
+	// existing spaces are left alone
+	suite.Equal(pangu.SpacingText(`（ Note ）说明`), `（ Note ）说明`)
+}
+
 func (suite *PanguTestSuite) TestPipe() {
 	suite.Equal(pangu.SpacingText(`前面|後面`), `前面 | 後面`)
 	suite.Equal(pangu.SpacingText(`前面 | 後面`), `前面 | 後面`)
@@ -344,6 +569,18 @@ func (suite *PanguTestSuite) TestSingleQuote() {
 	suite.Equal(pangu.SpacingText(`陳上進 likes 林依諾's status.`), `陳上進 likes 林依諾's status.`)
 }
 
+func (suite *PanguTestSuite) TestFunctionCall() {
+	suite.Equal(pangu.SpacingText(`运行func("参数")后`), `运行 func("参数") 后`)
+	suite.Equal(pangu.SpacingText(`调用print('你好')函数`), `调用 print('你好') 函数`)
+	suite.Equal(pangu.SpacingText(`执行foo("a", bar(1))然后`), `执行 foo("a", bar(1)) 然后`)
+	suite.Equal(pangu.SpacingText(`运行obj.method("参数", 'x')后`), `运行 obj.method("参数", 'x') 后`)
+	suite.Equal(pangu.SpacingText(`运行 func("参数") 后`), `运行 func("参数") 后`)
+
+	// calls without quoted arguments are spaced as before
+	suite.Equal(pangu.SpacingText(`函数f(x)的值`), `函数 f(x) 的值`)
+	suite.Equal(pangu.SpacingText(`iPhone(包括XS和XR)很贵`), `iPhone(包括 XS 和 XR) 很贵`)
+}
+
 func (suite *PanguTestSuite) TestLessThan() {
 	suite.Equal(pangu.SpacingText(`前面<後面`), `前面 < 後面`)
 	suite.Equal(pangu.SpacingText(`前面 < 後面`), `前面 < 後面`)
@@ -362,12 +599,51 @@ func (suite *PanguTestSuite) TestLessThan() {
 	suite.Equal(pangu.SpacingText(`head <中文123漢字> tail`), `head <中文 123 漢字> tail`)
 }
 
+func (suite *PanguTestSuite) TestPlaceholder() {
+	suite.Equal(pangu.SpacingText(`输入<username>然后`), `输入 <username> 然后`)
+	suite.Equal(pangu.SpacingText(`按<KEY>键`), `按 <KEY> 键`)
+	suite.Equal(pangu.SpacingText(`使用<file-path>参数`), `使用 <file-path> 参数`)
+	suite.Equal(pangu.SpacingText(`<name>是名字`), `<name> 是名字`)
+	suite.Equal(pangu.SpacingText(`输入 <username> 然后`), `输入 <username> 然后`)
+
+	// operators next to a placeholder are spaced on both sides
+	suite.Equal(pangu.SpacingText(`设<name>=张三`), `设 <name> = 张三`)
+	suite.Equal(pangu.SpacingText(`张三=<name>`), `张三 = <name>`)
+
+	// comparisons are not placeholders
+	suite.Equal(pangu.SpacingText(`如果a<b且c>d则`), `如果 a<b 且 c>d 则`)
+	suite.Equal(pangu.SpacingText(`当x<10时`), `当 x<10 时`)
+}
+
 func (suite *PanguTestSuite) TestComma() {
 	suite.Equal(pangu.SpacingText(`前面,後面`), `前面, 後面`)
 	suite.Equal(pangu.SpacingText(`前面 , 後面`), `前面 , 後面`)
 	suite.Equal(pangu.SpacingText(`前面, 後面`), `前面, 後面`)
 }
 
+func (suite *PanguTestSuite) TestCommaBetweenCJKAndLatin() {
+	// ASCII comma
+	suite.Equal(pangu.SpacingText(`你好,world`), `你好, world`)
+	suite.Equal(pangu.SpacingText(`world,你好`), `world, 你好`)
+	suite.Equal(pangu.SpacingText(`你好, world`), `你好, world`)
+	suite.Equal(pangu.SpacingText(`world, 你好`), `world, 你好`)
+
+	// a space before the comma goes after it
+	suite.Equal(pangu.SpacingText(`你好 ,world`), `你好, world`)
+	suite.Equal(pangu.SpacingText(`world ,你好`), `world, 你好`)
+	suite.Equal(pangu.SpacingText(`你好 , world`), `你好, world`)
+	suite.Equal(pangu.SpacingText(`world , 你好`), `world, 你好`)
+	suite.Equal(pangu.SpacingText(`你好, world`), pangu.SpacingText(pangu.SpacingText(`你好 ,world`)))
+
+	// full-width comma
+	suite.Equal(pangu.SpacingText(`你好，world`), `你好，world`)
+	suite.Equal(pangu.SpacingText(`world，你好`), `world，你好`)
+
+	// neither side is CJK
+	suite.Equal(pangu.SpacingText(`hello,world`), `hello,world`)
+	suite.Equal(pangu.SpacingText(`共1,000元`), `共 1,000 元`)
+}
+
 func (suite *PanguTestSuite) TestGreaterThan() {
 	suite.Equal(pangu.SpacingText(`前面>後面`), `前面 > 後面`)
 	suite.Equal(pangu.SpacingText(`前面 > 後面`), `前面 > 後面`)
@@ -389,12 +665,38 @@ func (suite *PanguTestSuite) TestPeriod() {
 	suite.Equal(pangu.SpacingText(`前面……後面`), `前面…… 後面`)
 }
 
+func (suite *PanguTestSuite) TestAbbreviationBeforeFullWidthPeriod() {
+	// the abbreviation keeps its period and the full-width one follows
+	// it tightly
+	suite.Equal(pangu.SpacingText(`这是U.S.。`), `这是 U.S.。`)
+	suite.Equal(pangu.SpacingText(`这是U.S.。然后`), `这是 U.S.。然后`)
+	suite.Equal(pangu.SpacingText(`例如e.g.。`), `例如 e.g.。`)
+	suite.Equal(pangu.SpacingText(`来自U.S.A.，他说`), `来自 U.S.A.，他说`)
+	suite.Equal(pangu.SpacingText(`口号是etc.！`), `口号是 etc.！`)
+	suite.Equal(pangu.SpacingText(`这是 U.S.。`), `这是 U.S.。`)
+
+	// without full-width punctuation, the abbreviation is spaced on both
+	// sides
+	suite.Equal(pangu.SpacingText(`美国U.S.的政策`), `美国 U.S. 的政策`)
+}
+
 func (suite *PanguTestSuite) TestQuestionMark() {
 	suite.Equal(pangu.SpacingText(`前面?後面`), `前面? 後面`)
 	suite.Equal(pangu.SpacingText(`前面 ? 後面`), `前面 ? 後面`)
 	suite.Equal(pangu.SpacingText(`前面? 後面`), `前面? 後面`)
 }
 
+func (suite *PanguTestSuite) TestAcronymBeforeFullWidthPunctuation() {
+	suite.Equal(pangu.SpacingText(`使用API。`), `使用 API。`)
+	suite.Equal(pangu.SpacingText(`这是HTML，然后`), `这是 HTML，然后`)
+	suite.Equal(pangu.SpacingText(`格式是JSON：如下`), `格式是 JSON：如下`)
+	suite.Equal(pangu.SpacingText(`别用CSS；改用`), `别用 CSS；改用`)
+	suite.Equal(pangu.SpacingText(`下载PDF！`), `下载 PDF！`)
+	suite.Equal(pangu.SpacingText(`支持SVG？`), `支持 SVG？`)
+	suite.Equal(pangu.SpacingText(`支持PNG、JPG、GIF等格式`), `支持 PNG、JPG、GIF 等格式`)
+	suite.Equal(pangu.SpacingText(`使用 API。`), `使用 API。`)
+}
+
 func (suite *PanguTestSuite) TestSlash() {
 	suite.Equal(pangu.SpacingText(`前面/後面`), `前面 / 後面`)
 	suite.Equal(pangu.SpacingText(`前面 / 後面`), `前面 / 後面`)
@@ -409,6 +711,19 @@ func (suite *PanguTestSuite) TestSlash() {
 	// suite.Equal(pangu.SpacingText(`陳上進/Vinta/Mollie`), `陳上進 / Vinta / Mollie`)
 }
 
+func (suite *PanguTestSuite) TestSpacingArgs() {
+	args := []string{"當你凝視著bug", "", "V", "中", "與 PM 戰鬥的人"}
+	suite.Equal(pangu.SpacingArgs(args), []string{"當你凝視著 bug", "", "V", "中", "與 PM 戰鬥的人"})
+	suite.Equal(args[0], "當你凝視著bug")
+
+	suite.Nil(pangu.SpacingArgs(nil))
+	suite.Equal(pangu.SpacingArgs([]string{}), []string{})
+
+	s, err := pangu.NewSpacer(pangu.Options{NoSpaceWords: []string{"微信Pay"}})
+	suite.Nil(err)
+	suite.Equal(s.SpacingArgs([]string{"用微信Pay付款", "用Apple Pay付款"}), []string{"用微信Pay 付款", "用 Apple Pay 付款"})
+}
+
 func (suite *PanguTestSuite) TestSpacingFile() {
 	input := "_fixtures/test_file.txt"
 	output := "_fixtures/test_file.pangu.txt"
@@ -435,9 +750,128 @@ func (suite *PanguTestSuite) TestSpacingFileNoNewlineAtEOF() {
 	suite.Equal(md5Of(output), md5Of("_fixtures/test_file_no_eof_newline.expected.txt"))
 }
 
+func (suite *PanguTestSuite) TestSpacingFileMixedLineEndings() {
+	input := "_fixtures/test_file_mixed_eol.txt"
+	output := "_fixtures/test_file_mixed_eol.pangu.txt"
+
+	fw, err := os.Create(output)
+	checkError(err)
+	defer fw.Close()
+
+	err = pangu.SpacingFile(input, fw)
+	suite.Nil(err)
+	suite.Equal(md5Of(output), md5Of("_fixtures/test_file_mixed_eol.expected.txt"))
+}
+
+func (suite *PanguTestSuite) TestSpacingTextLineByLine() {
+	// brackets and quotes spanning lines keep their line breaks
+	suite.Equal(pangu.SpacingText("中文(\nabc\n)中文"), "中文 (\nabc\n) 中文")
+	suite.Equal(pangu.SpacingText("中文[\n1,2\n]中文"), "中文 [\n1,2\n] 中文")
+	suite.Equal(pangu.SpacingText("中文\u201c\n你好\n\u201d中文"), "中文 \u201c\n你好\n\u201d 中文")
+	suite.Equal(pangu.SpacingText("他說\"\nhello\n\""), "他說 \"\nhello\n\"")
+
+	// a quote is spaced against the CJK before it, whatever the next line
+	suite.Equal(pangu.SpacingText("中文'\nabc'"), "中文 '\nabc'")
+	suite.Equal(pangu.SpacingText("中文'\r\nabc'"), "中文 '\r\nabc'")
+
+	// the result is that of spacing each line
+	text := "中文(\r\nabc\r\n)中文\n中文'\rabc'"
+	var lines []string
+	for _, line := range []string{"中文(\r\n", "abc\r\n", ")中文\n", "中文'\r", "abc'"} {
+		lines = append(lines, pangu.SpacingText(line))
+	}
+	suite.Equal(pangu.SpacingText(text), strings.Join(lines, ""))
+}
+
+func (suite *PanguTestSuite) TestMixedLineEndings() {
+	suite.Equal(pangu.SpacingText("中文abc\r\n中文abc\n中文abc\r中文abc"), "中文 abc\r\n中文 abc\n中文 abc\r中文 abc")
+
+	// a stray \r ends a line, so it isn't taken for whitespace to remove
+	suite.Equal(pangu.SpacingText("前面(\rabc)"), "前面 (\rabc)")
+	suite.Equal(pangu.SpacingText("中文(abc \r)"), "中文 (abc \r)")
+	suite.Equal(pangu.SpacingText("中文\rabc"), "中文\rabc")
+}
+
 func (suite *PanguTestSuite) TestSpacingFileNoSuchFile() {
 	input := "_fixtures/none.exist"
 
 	err := pangu.SpacingFile(input, ioutil.Discard)
 	suite.EqualError(err, "open _fixtures/none.exist: no such file or directory")
 }
+
+func (suite *PanguTestSuite) TestIsFileSpaced() {
+	spaced, err := pangu.IsFileSpaced("_fixtures/test_file.expected.txt")
+	suite.Nil(err)
+	suite.True(spaced)
+
+	spaced, err = pangu.IsFileSpaced("_fixtures/test_file_no_eof_newline.expected.txt")
+	suite.Nil(err)
+	suite.True(spaced)
+
+	spaced, err = pangu.IsFileSpaced("_fixtures/test_file.txt")
+	suite.Nil(err)
+	suite.False(spaced)
+}
+
+func (suite *PanguTestSuite) TestIsFileSpacedBinaryFile() {
+	spaced, err := pangu.IsFileSpaced("_fixtures/test_file.bin")
+	suite.Equal(err, pangu.ErrBinaryFile)
+	suite.False(spaced)
+
+	spaced, err = pangu.IsFileSpaced("_fixtures/test_file_utf16.txt")
+	suite.Equal(err, pangu.ErrUnsupportedEncoding)
+	suite.False(spaced)
+}
+
+func (suite *PanguTestSuite) TestIsFileSpacedNoSuchFile() {
+	_, err := pangu.IsFileSpaced("_fixtures/none.exist")
+	suite.EqualError(err, "open _fixtures/none.exist: no such file or directory")
+}
+
+func (suite *PanguTestSuite) TestCJKRangeTable() {
+	table := pangu.CJKRangeTable()
+
+	blocks := [][2]rune{
+		{'⺀', '⻿'}, // CJK Radicals Supplement
+		{'⼀', '⿟'}, // Kangxi Radicals
+		{'぀', 'ゟ'}, // Hiragana
+		{'゠', 'ヿ'}, // Katakana
+		{'㄀', 'ㄯ'}, // Bopomofo
+		{'㈀', '㋿'}, // Enclosed CJK Letters and Months
+		{'㐀', '䶿'}, // CJK Unified Ideographs Extension A
+		{'一', '鿿'}, // CJK Unified Ideographs
+		{'豈', '﫿'}, // CJK Compatibility Ideographs
+	}
+	for _, b := range blocks {
+		suite.True(unicode.Is(table, b[0]), "%U", b[0])
+		suite.True(unicode.Is(table, b[1]), "%U", b[1])
+	}
+
+	for _, r := range []rune{'⹿', '⿠', '〿', '㄰', 'ㇿ', '䷀', 'ﬀ', 'A', '1'} {
+		suite.False(unicode.Is(table, r), "%U", r)
+	}
+}
+
+func (suite *PanguTestSuite) TestANSRangeTable() {
+	table := pangu.ANSRangeTable()
+
+	for _, r := range "AZaz09`$%^&*-=+\\|/¡ÿͰϿ‑•‧₠€⃏⅐↏①⓿" {
+		suite.True(unicode.Is(table, r), "%U", r)
+	}
+
+	for _, r := range "@#~!;:,.?_()[]{}<>\"'  ·‐‒‡‣…⅏←中" {
+		suite.False(unicode.Is(table, r), "%U", r)
+	}
+}
+
+func (suite *PanguTestSuite) TestRangeTablesAreCopies() {
+	for _, table := range []*unicode.RangeTable{pangu.CJKRangeTable(), pangu.ANSRangeTable()} {
+		for i := range table.R16 {
+			table.R16[i] = unicode.Range16{Lo: 0, Hi: 0, Stride: 1}
+		}
+	}
+
+	suite.True(unicode.Is(pangu.CJKRangeTable(), '中'))
+	suite.True(unicode.Is(pangu.ANSRangeTable(), 'A'))
+	suite.Equal(pangu.SpacingText(`當你凝視著bug，bug也凝視著你`), `當你凝視著 bug，bug 也凝視著你`)
+}
@@ -0,0 +1,89 @@
+package pangu
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+)
+
+// Position locates a space inserted by paranoid text spacing in the
+// original input. Line and Column are 1-based and Column counts runes;
+// the space goes right before the rune at Column.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// SpacingPositions reads r line by line and calls fn with the position
+// of every space that paranoid text spacing would insert, in order.
+// It returns the first error encountered while reading, if any.
+func SpacingPositions(r io.Reader, fn func(Position)) error {
+	return defaultSpacer.SpacingPositions(r, fn)
+}
+
+// SpacingPositions is like the package-level SpacingPositions but uses
+// the rules and options of s. If s was created with a TabWidth, tabs
+// advance the column to the next tab stop.
+func (s *Spacer) SpacingPositions(r io.Reader, fn func(Position)) error {
+	n := 0
+	return s.eachLine(r, func(line string) error {
+		n++
+
+		i, col := 0, 1
+		for _, h := range diff(line, s.SpacingText(line)) {
+			if h.Original != "" {
+				continue
+			}
+			for i < h.Start {
+				r, size := utf8.DecodeRuneInString(line[i:])
+				col = s.advance(col, r)
+				i += size
+			}
+			fn(Position{Line: n, Column: col})
+		}
+
+		return nil
+	})
+}
+
+// SpacingOffsets reads r line by line, writes the spaced lines to w and
+// calls fn with the byte offset in r of every space inserted, in order.
+// Only one line is held in memory at a time, so r may be arbitrarily
+// large. It returns the first error encountered, if any.
+func SpacingOffsets(r io.Reader, w io.Writer, fn func(offset int64)) error {
+	return defaultSpacer.SpacingOffsets(r, w, fn)
+}
+
+// SpacingOffsets is like the package-level SpacingOffsets but uses the
+// rules and options of s.
+func (s *Spacer) SpacingOffsets(r io.Reader, w io.Writer, fn func(offset int64)) error {
+	bw := bufio.NewWriter(w)
+
+	var base int64
+	err := s.eachLine(r, func(line string) error {
+		result := s.SpacingText(line)
+		for _, h := range diff(line, result) {
+			if h.Original == "" {
+				fn(base + int64(h.Start))
+			}
+		}
+		base += int64(len(line))
+
+		_, err := bw.WriteString(result)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// advance returns the column following the rune r at column col.
+func (s *Spacer) advance(col int, r rune) int {
+	if r == '\t' && s.tabWidth > 0 {
+		return (col-1)/s.tabWidth*s.tabWidth + s.tabWidth + 1
+	}
+
+	return col + 1
+}
@@ -0,0 +1,138 @@
+package pangu_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/vinta/pangu"
+	"os"
+	"strings"
+	"testing/iotest"
+)
+
+func (suite *PanguTestSuite) TestSpacingPositions() {
+	text := "當你凝視著bug，bug也凝視著你\n" +
+		"\n" +
+		"與 PM 戰鬥的人\n" +
+		"前面#H2G2後面"
+
+	var positions []pangu.Position
+	err := pangu.SpacingPositions(strings.NewReader(text), func(pos pangu.Position) {
+		positions = append(positions, pos)
+	})
+	suite.Nil(err)
+	suite.Equal(positions, []pangu.Position{
+		{Line: 1, Column: 6},
+		{Line: 1, Column: 13},
+		{Line: 4, Column: 3},
+		{Line: 4, Column: 8},
+	})
+}
+
+func (suite *PanguTestSuite) TestSpacingPositionsTabWidth() {
+	text := "\t中文English\n" +
+		"a\tb\t中文English"
+
+	collect := func(s *pangu.Spacer) []pangu.Position {
+		var positions []pangu.Position
+		err := s.SpacingPositions(strings.NewReader(text), func(pos pangu.Position) {
+			positions = append(positions, pos)
+		})
+		suite.Nil(err)
+		return positions
+	}
+
+	s, err := pangu.NewSpacer(pangu.Options{})
+	suite.Nil(err)
+	suite.Equal(collect(s), []pangu.Position{
+		{Line: 1, Column: 4},
+		{Line: 2, Column: 7},
+	})
+
+	s, err = pangu.NewSpacer(pangu.Options{TabWidth: 4})
+	suite.Nil(err)
+	suite.Equal(collect(s), []pangu.Position{
+		{Line: 1, Column: 7},
+		{Line: 2, Column: 11},
+	})
+}
+
+func (suite *PanguTestSuite) TestSpacingPositionsFile() {
+	fr, err := os.Open("_fixtures/test_file.txt")
+	checkError(err)
+	defer fr.Close()
+
+	var positions []pangu.Position
+	err = pangu.SpacingPositions(fr, func(pos pangu.Position) {
+		positions = append(positions, pos)
+	})
+	suite.Nil(err)
+	suite.Equal(positions, []pangu.Position{
+		{Line: 1, Column: 10},
+		{Line: 3, Column: 38},
+		{Line: 3, Column: 46},
+	})
+}
+
+func (suite *PanguTestSuite) TestSpacingOffsets() {
+	text := "當你凝視著bug，bug也凝視著你\n前面#H2G2後面"
+
+	var buf bytes.Buffer
+	var offsets []int64
+	err := pangu.SpacingOffsets(strings.NewReader(text), &buf, func(offset int64) {
+		offsets = append(offsets, offset)
+	})
+	suite.Nil(err)
+	suite.Equal(buf.String(), pangu.SpacingText(text))
+	suite.Equal(offsets, []int64{15, 24, 46, 51})
+}
+
+func (suite *PanguTestSuite) TestSpacingOffsetsLargeInput() {
+	lines := []string{
+		"當你凝視著bug，bug也凝視著你\n",
+		"與 PM 戰鬥的人，應當小心自己不要成為 PM\n",
+		"\n",
+		"新八的構造成分有95%是眼鏡、3%是水、2%是垃圾\n",
+	}
+	text := strings.Repeat(strings.Join(lines, ""), 2000)
+
+	var want []int64
+	for _, h := range pangu.SpacingHunks(text) {
+		if h.Original == "" {
+			want = append(want, int64(h.Start))
+		}
+	}
+
+	var buf bytes.Buffer
+	var offsets []int64
+	r := iotest.OneByteReader(strings.NewReader(text))
+	err := pangu.SpacingOffsets(r, &buf, func(offset int64) {
+		offsets = append(offsets, offset)
+	})
+	suite.Nil(err)
+	suite.Equal(len(offsets), 12000)
+	suite.Equal(offsets, want)
+	suite.Equal(buf.String(), pangu.SpacingText(text))
+}
+
+// failingWriter fails every write with err.
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
+
+func (suite *PanguTestSuite) TestSpacingOffsetsWriteError() {
+	text := strings.Repeat("當你凝視著bug，bug也凝視著你\n", 10000)
+	r := strings.NewReader(text)
+	werr := errors.New("disk full")
+
+	calls := 0
+	err := pangu.SpacingOffsets(r, failingWriter{werr}, func(offset int64) {
+		calls++
+	})
+	suite.Equal(err, werr)
+	suite.True(r.Len() > 0)
+	suite.True(calls < 20000)
+}
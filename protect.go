@@ -0,0 +1,387 @@
+package pangu
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sentinel stands in for the hidden middle of a protected span. It is a
+// noncharacter, so no rule ever matches it.
+const sentinel = '\ufdd0'
+
+// atom stands in for the whole of an atomic span. It is a noncharacter
+// too, but the rules treat it as a half-width character, so that the
+// span is spaced against CJK on both sides whatever it is made of.
+const atom = '\ufdd1'
+
+// span is a byte range [start, end) of text to protect. If inner is not
+// nil, it is applied to the hidden middle of the span. If atomic is set,
+// the whole span is hidden, not only its middle.
+type span struct {
+	start, end int
+	inner      func(string) string
+	atomic     bool
+}
+
+// protect hides the middle of every span of text behind a sentinel.
+// The first and last runes of each span are kept so that the span is
+// still spaced against its neighbors, while nothing inside it can be.
+// Atomic spans are hidden whole behind an atom instead.
+// The spans must be sorted and must not overlap. It returns the masked
+// text and the hidden middles, for restore.
+func protect(text string, spans []span) (string, []string) {
+	if len(spans) == 0 || strings.ContainsAny(text, sentinels) {
+		return text, nil
+	}
+
+	var buf bytes.Buffer
+	var middles []string
+
+	last := 0
+	for _, sp := range spans {
+		if sp.atomic {
+			buf.WriteString(text[last:sp.start])
+			buf.WriteRune(atom)
+			middles = append(middles, text[sp.start:sp.end])
+			last = sp.end
+			continue
+		}
+
+		_, first := utf8.DecodeRuneInString(text[sp.start:])
+		_, size := utf8.DecodeLastRuneInString(text[:sp.end])
+		if sp.end-sp.start <= first {
+			continue
+		}
+
+		middle := text[sp.start+first : sp.end-size]
+		if sp.inner != nil {
+			middle = sp.inner(middle)
+		}
+
+		buf.WriteString(text[last : sp.start+first])
+		buf.WriteRune(sentinel)
+		middles = append(middles, middle)
+		last = sp.end - size
+	}
+	buf.WriteString(text[last:])
+
+	return buf.String(), middles
+}
+
+// cut is a piece of text taken out before spacing, along with where it
+// goes back. A space inserted right where a cut was goes after it,
+// unless the cut is open.
+type cut struct {
+	pos  int // byte offset in the text it was taken out of
+	text string
+	open bool
+}
+
+// reinsert puts the cuts taken out of stripped back into spaced, its
+// spaced form.
+func reinsert(stripped, spaced string, cuts []cut) string {
+	var buf bytes.Buffer
+
+	last := 0
+	flush := func(end int) {
+		for len(cuts) > 0 && cuts[0].pos < end {
+			buf.WriteString(stripped[last:cuts[0].pos])
+			buf.WriteString(cuts[0].text)
+			last = cuts[0].pos
+			cuts = cuts[1:]
+		}
+		buf.WriteString(stripped[last:end])
+		last = end
+	}
+
+	for _, h := range diff(stripped, spaced) {
+		flush(h.Start)
+		for len(cuts) > 0 && cuts[0].pos == h.Start && !cuts[0].open {
+			buf.WriteString(cuts[0].text)
+			cuts = cuts[1:]
+		}
+		buf.WriteString(h.Spaced)
+		// the open ones, and any within text the hunk replaced
+		for len(cuts) > 0 && (cuts[0].pos == h.Start || cuts[0].pos < h.End) {
+			buf.WriteString(cuts[0].text)
+			cuts = cuts[1:]
+		}
+		last = h.End
+	}
+	flush(len(stripped))
+	for _, d := range cuts {
+		buf.WriteString(d.text)
+	}
+
+	return buf.String()
+}
+
+// stripControls removes the explicit bidirectional formatting
+// characters, like LRE and PDF, from text, and if all is set the other
+// control characters but whitespace too, like NUL. The cuts of the
+// characters opening an embedding, override or isolate are open, so that
+// spaces go outside of it.
+func stripControls(text string, all bool) (string, []cut) {
+	var buf bytes.Buffer
+	var cuts []cut
+
+	for _, r := range text {
+		open := strings.ContainsRune(bidiOpeners, r)
+		if !open && !strings.ContainsRune(bidiClosers, r) && (!all || !unicode.IsControl(r) || unicode.IsSpace(r)) {
+			buf.WriteRune(r)
+			continue
+		}
+		// An open cut takes in what follows it, while the characters
+		// before an opener stay in a cut of their own, so that a space
+		// can go between the two.
+		if n := len(cuts); n > 0 && cuts[n-1].pos == buf.Len() && (cuts[n-1].open || !open) {
+			cuts[n-1].text += string(r)
+			continue
+		}
+		cuts = append(cuts, cut{pos: buf.Len(), text: string(r), open: open})
+	}
+
+	return buf.String(), cuts
+}
+
+// bidiOpeners are LRE, RLE, LRO, RLO, LRI, RLI and FSI, and bidiClosers
+// are PDF and PDI, which end what the openers start.
+const (
+	bidiOpeners = "\u202a\u202b\u202d\u202e\u2066\u2067\u2068"
+	bidiClosers = "\u202c\u2069"
+)
+
+// sentinels holds both sentinel and atom, which are the same length.
+const sentinels = string(sentinel) + string(atom)
+
+// restore puts the middles hidden by protect back in place.
+func restore(text string, middles []string) string {
+	if len(middles) == 0 {
+		return text
+	}
+
+	var buf bytes.Buffer
+
+	for _, m := range middles {
+		i := strings.IndexAny(text, sentinels)
+		buf.WriteString(text[:i])
+		buf.WriteString(m)
+		text = text[i+utf8.RuneLen(sentinel):]
+	}
+	buf.WriteString(text)
+
+	return buf.String()
+}
+
+// wordsRegexp returns a regexp matching any of words, preferring the
+// longest one, or nil if there are no words.
+func wordsRegexp(words []string, ignoreCase bool) *regexp.Regexp {
+	if len(words) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = regexp.QuoteMeta(w)
+	}
+	sort.Sort(byLength(quoted))
+
+	expr := strings.Join(quoted, "|")
+	if ignoreCase {
+		expr = "(?i)" + expr
+	}
+
+	return regexp.MustCompile(expr)
+}
+
+type byLength []string
+
+func (s byLength) Len() int           { return len(s) }
+func (s byLength) Less(i, j int) bool { return len(s[i]) > len(s[j]) }
+func (s byLength) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// matchSpans returns the spans of text matched by re.
+func matchSpans(re *regexp.Regexp, text string) []span {
+	if re == nil {
+		return nil
+	}
+
+	var spans []span
+	for _, loc := range re.FindAllStringIndex(text, -1) {
+		spans = append(spans, span{start: loc[0], end: loc[1]})
+	}
+
+	return spans
+}
+
+// atomicSpans returns the spans of text matched by any of res, as
+// atomic spans.
+func atomicSpans(res []*regexp.Regexp, text string) []span {
+	var spans []span
+	for _, re := range res {
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if loc[0] < loc[1] {
+				spans = append(spans, span{start: loc[0], end: loc[1], atomic: true})
+			}
+		}
+	}
+
+	return spans
+}
+
+// mergeSpans sorts spans from several sources and drops the ones
+// overlapping an earlier, or at the same start a longer, span.
+func mergeSpans(spans ...[]span) []span {
+	var all []span
+	for _, s := range spans {
+		all = append(all, s...)
+	}
+	if len(all) < 2 {
+		return all
+	}
+	sort.Sort(byStart(all))
+
+	merged := all[:1]
+	for _, sp := range all[1:] {
+		if sp.start >= merged[len(merged)-1].end {
+			merged = append(merged, sp)
+		}
+	}
+
+	return merged
+}
+
+type byStart []span
+
+func (s byStart) Len() int { return len(s) }
+func (s byStart) Less(i, j int) bool {
+	if s[i].start != s[j].start {
+		return s[i].start < s[j].start
+	}
+	return s[i].end > s[j].end
+}
+func (s byStart) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// callSpans returns the spans of inline function calls whose arguments
+// include a quoted string, like func("参数"). Such calls are easily
+// mangled by the quote and bracket rules.
+func callSpans(text string, inner func(string) string) []span {
+	var spans []span
+
+	for _, loc := range call_open.FindAllStringIndex(text, -1) {
+		if len(spans) > 0 && loc[0] < spans[len(spans)-1].end {
+			continue
+		}
+
+		end, ok := closingParen(text, loc[1])
+		if !ok || !strings.ContainsAny(text[loc[1]:end], "\"'") {
+			continue
+		}
+		spans = append(spans, span{start: loc[0], end: end + 1, inner: inner})
+	}
+
+	return spans
+}
+
+// closingParen returns the index of the parenthesis closing the one
+// right before text[i], skipping over quoted strings.
+func closingParen(text string, i int) (int, bool) {
+	depth := 1
+	for ; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '"', '\'':
+			j := strings.IndexByte(text[i+1:], c)
+			if j == -1 {
+				return 0, false
+			}
+			i += j + 1
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i, true
+			}
+		case '\n':
+			return 0, false
+		}
+	}
+
+	return 0, false
+}
+
+// literalSpans returns the spans of inline array and object literals,
+// like [1,2,3] and {"a":{"b":1}}, as atomic spans. A balanced pair of
+// brackets or braces counts as a literal if its contents, outside of
+// quoted strings, have no CJK and do have a comma, a colon, a quote or
+// a nested literal. Brackets followed by a parenthesis, as in Markdown
+// [links](url), are left alone.
+func literalSpans(text string) []span {
+	var spans []span
+
+	for i := 0; i < len(text); i++ {
+		if c := text[i]; c != '[' && c != '{' {
+			continue
+		}
+
+		end, ok := closingBracket(text, i)
+		if !ok || !isLiteral(text[i+1:end]) || strings.HasPrefix(text[end+1:], "(") {
+			continue
+		}
+		spans = append(spans, span{start: i, end: end + 1, atomic: true})
+		i = end
+	}
+
+	return spans
+}
+
+// closingBracket returns the index of the bracket or brace closing the
+// one at text[i], skipping over quoted strings. Mismatched pairs, as in
+// [1}, are not closed.
+func closingBracket(text string, i int) (int, bool) {
+	var closers []byte
+	for ; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '"', '\'':
+			j := strings.IndexByte(text[i+1:], c)
+			if j == -1 {
+				return 0, false
+			}
+			i += j + 1
+		case '[':
+			closers = append(closers, ']')
+		case '{':
+			closers = append(closers, '}')
+		case ']', '}':
+			if closers[len(closers)-1] != c {
+				return 0, false
+			}
+			closers = closers[:len(closers)-1]
+			if len(closers) == 0 {
+				return i, true
+			}
+		case '\n':
+			return 0, false
+		}
+	}
+
+	return 0, false
+}
+
+// isLiteral reports whether contents, the text between the brackets of
+// a balanced pair, looks like the contents of an array or object
+// literal.
+func isLiteral(contents string) bool {
+	bare := quoted_string.ReplaceAllString(contents, "\"\"")
+	for _, r := range bare {
+		if unicode.Is(cjkTable, r) {
+			return false
+		}
+	}
+
+	return strings.ContainsAny(bare, ",:\"'[{")
+}
@@ -0,0 +1,37 @@
+package pangu
+
+// SpacingResult is everything SpacingTextResult finds out about a text.
+type SpacingResult struct {
+	// Text is the spaced text, as returned by SpacingText.
+	Text string
+
+	// Insertions is the number of places where whitespace was added.
+	Insertions int
+
+	// Changed reports whether Text differs from the original text.
+	Changed bool
+
+	// Hunks are the changes, as returned by SpacingHunks.
+	Hunks []Hunk
+}
+
+// SpacingTextResult performs paranoid text spacing on text once and
+// returns the spaced text together with a description of the changes.
+func SpacingTextResult(text string) SpacingResult {
+	return defaultSpacer.SpacingTextResult(text)
+}
+
+// SpacingTextResult is like the package-level SpacingTextResult but uses
+// the rules and options of s.
+func (s *Spacer) SpacingTextResult(text string) SpacingResult {
+	result := SpacingResult{Text: s.SpacingText(text)}
+	result.Changed = result.Text != text
+	if !result.Changed {
+		return result
+	}
+
+	result.Hunks = diff(text, result.Text)
+	result.Insertions = insertions(result.Hunks)
+
+	return result
+}
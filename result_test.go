@@ -0,0 +1,32 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+	"strings"
+)
+
+func (suite *PanguTestSuite) TestSpacingTextResult() {
+	for _, text := range []string{
+		`當你凝視著bug，bug也凝視著你`,
+		`前面( 中文123漢字 )後面`,
+		`前面 (中文 123 漢字) 後面`,
+		``,
+	} {
+		result := pangu.SpacingTextResult(text)
+		suite.Equal(result.Text, pangu.SpacingText(text))
+		suite.Equal(result.Changed, result.Text != text)
+		suite.Equal(result.Hunks, pangu.SpacingHunks(text))
+
+		var positions []pangu.Position
+		pangu.SpacingPositions(strings.NewReader(text), func(pos pangu.Position) {
+			positions = append(positions, pos)
+		})
+		suite.Equal(result.Insertions, len(positions))
+	}
+
+	result := pangu.SpacingTextResult(`前面( 中文123漢字 )後面`)
+	suite.Equal(result.Text, `前面 (中文 123 漢字) 後面`)
+	suite.Equal(result.Insertions, 4)
+	suite.True(result.Changed)
+	suite.Len(result.Hunks, 6)
+}
@@ -0,0 +1,83 @@
+package pangu
+
+import (
+	"bufio"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SpacingSplitFunc wraps split, a bufio.SplitFunc, so that each token it
+// returns is spaced, including at its boundary with the previous token.
+// Only a token that split returns along with all the bytes it advances
+// over is spaced against the next one, so the tokens of splits that drop
+// delimiters, like bufio.ScanLines, are each spaced on their own. The
+// returned function keeps the end of the previous token, so it must be
+// used by a single bufio.Scanner.
+func SpacingSplitFunc(split bufio.SplitFunc) bufio.SplitFunc {
+	return defaultSpacer.SpacingSplitFunc(split)
+}
+
+// SpacingSplitFunc is like the package-level SpacingSplitFunc but uses
+// the rules and options of s.
+func (s *Spacer) SpacingSplitFunc(split bufio.SplitFunc) bufio.SplitFunc {
+	var last string
+
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := split(data, atEOF)
+		if len(token) == 0 {
+			return advance, token, err
+		}
+
+		text := string(token)
+		if advance != len(token) {
+			last = ""
+			return advance, []byte(s.SpacingText(text)), err
+		}
+
+		spaced := s.spacingAfter(last, text)
+		last = carry(last + text)
+
+		return advance, []byte(spaced), err
+	}
+}
+
+// spacingAfter performs paranoid text spacing on text as it follows
+// last, a carry of the previous token. Spaces that go between the last
+// base rune of last and the runes carried along with it are put at the
+// start of text, since last has already been returned.
+func (s *Spacer) spacingAfter(last, text string) string {
+	_, base := utf8.DecodeRuneInString(last)
+
+	joined := last + text
+	hunks := diff(joined, s.SpacingText(joined))
+	for i, h := range hunks {
+		switch {
+		case h.Start >= len(last):
+			hunks[i].Start -= len(last)
+			hunks[i].End -= len(last)
+		case h.Original == "" && h.Start >= base:
+			hunks[i].Start, hunks[i].End = 0, 0
+		default:
+			return s.SpacingText(text)
+		}
+	}
+
+	return ApplyHunks(text, hunks)
+}
+
+// carry returns the end of text that decides how the text after it is
+// spaced: its last base rune, along with the IGN runes and bidi controls
+// that follow it, which are transparent to the rules.
+func carry(text string) string {
+	i := len(text)
+	for i > 0 {
+		r, size := utf8.DecodeLastRuneInString(text[:i])
+		i -= size
+		if !unicode.Is(ignTable, r) && !strings.ContainsRune(bidiOpeners+bidiClosers, r) {
+			break
+		}
+	}
+
+	return text[i:]
+}
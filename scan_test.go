@@ -0,0 +1,79 @@
+package pangu_test
+
+import (
+	"bufio"
+	"github.com/vinta/pangu"
+	"strings"
+	"unicode/utf8"
+)
+
+// scanSentences is a bufio.SplitFunc that splits after each "。".
+func scanSentences(data []byte, atEOF bool) (int, []byte, error) {
+	if i := strings.Index(string(data), "。"); i != -1 {
+		return i + len("。"), data[:i+len("。")], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+
+	return 0, nil, nil
+}
+
+// scanPairs is a bufio.SplitFunc that splits after every second rune.
+func scanPairs(data []byte, atEOF bool) (int, []byte, error) {
+	i, n := 0, 0
+	for ; n < 2 && utf8.FullRune(data[i:]); n++ {
+		_, size := utf8.DecodeRune(data[i:])
+		i += size
+	}
+	if n < 2 && !atEOF || i == 0 {
+		return 0, nil, nil
+	}
+
+	return i, data[:i], nil
+}
+
+func scanAll(text string, split bufio.SplitFunc) []string {
+	var tokens []string
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Split(pangu.SpacingSplitFunc(split))
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+
+	return tokens
+}
+
+func (suite *PanguTestSuite) TestSpacingSplitFunc() {
+	// boundaries within tokens
+	suite.Equal(scanAll("我用Go寫程式。Go很好用。", scanSentences), []string{"我用 Go 寫程式。", "Go 很好用。"})
+
+	// boundaries at the splits
+	suite.Equal(scanAll("這是中文。English然後中文", scanSentences), []string{"這是中文。", "English 然後中文"})
+	suite.Equal(scanAll("中文abc", scanPairs), []string{"中文", " ab", "c"})
+	suite.Equal(scanAll("ab中文", scanPairs), []string{"ab", " 中文"})
+
+	// runes that are transparent to the rules end a token; a space due
+	// before a bidi opener that ends a token goes after it instead
+	suite.Equal(strings.Join(scanAll("cafe\u0301中文", bufio.ScanRunes), ""), "cafe\u0301 中文")
+	suite.Equal(strings.Join(scanAll("字\ufe00text", bufio.ScanRunes), ""), "字\ufe00 text")
+	suite.Equal(strings.Join(scanAll("中文\u200dEnglish", bufio.ScanRunes), ""), "中文\u200d English")
+	suite.Equal(strings.Join(scanAll("中文\u202aabc\u202c中文", bufio.ScanRunes), ""), "中文\u202a abc\u202c 中文")
+
+	// splits that drop delimiters don't space across them
+	suite.Equal(scanAll("中文\nabc\nabc中文", bufio.ScanLines), []string{"中文", "abc", "abc 中文"})
+	suite.Equal(scanAll("中文 abc中文", bufio.ScanWords), []string{"中文", "abc 中文"})
+
+	// the tokens add up to SpacingText of the input
+	for _, text := range []string{
+		"當你凝視著bug，bug也凝視著你",
+		"與PM戰鬥的人，應當小心自己不要成為PM",
+		"前面#H2G2後面",
+		"cafe\u0301中文",
+		"字\ufe00text",
+		"中文\u200dEnglish",
+	} {
+		suite.Equal(strings.Join(scanAll(text, scanPairs), ""), pangu.SpacingText(text))
+	}
+}
@@ -0,0 +1,584 @@
+package pangu
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Options configures a Spacer. The zero value gives the same behavior
+// as the package-level functions.
+type Options struct {
+	// Rules lists the names of the rules to run, in order. Rules may
+	// be reordered or left out; see DefaultRules for the known names.
+	// If Rules is empty, the default pipeline is used.
+	Rules []string
+
+	// ReplaceBoundaryTabs makes a single tab between CJK and half-width
+	// characters be replaced by a space. Other tabs, like indentation
+	// or runs of tabs aligning columns, are always left alone.
+	ReplaceBoundaryTabs bool
+
+	// NormalizeExistingSpacing makes wrong spaces already in the text be
+	// fixed too: spaces before full-width punctuation and closing
+	// brackets, as in "中文 ，", or after full-width opening brackets are
+	// removed, and runs of spaces between CJK and half-width characters
+	// are collapsed to one.
+	NormalizeExistingSpacing bool
+
+	// NormalizeOnly makes the fixes of NormalizeExistingSpacing the only
+	// changes made, for text that is already spaced, if inconsistently:
+	// no space is ever inserted where there was none. Rules and
+	// ReplaceBoundaryTabs are ignored.
+	NormalizeOnly bool
+
+	// NoSpaceWords lists words that are kept as they are, such as brand
+	// names mixing CJK and half-width characters like "微信Pay". No
+	// space is inserted inside them, but their boundaries with the
+	// surrounding text are spaced as usual.
+	NoSpaceWords []string
+
+	// NoSpaceWordsIgnoreCase makes NoSpaceWords match regardless of case,
+	// so that listing "iOS版" also keeps "IOS版" and "ios版" as they are.
+	// By default the words must match exactly.
+	NoSpaceWordsIgnoreCase bool
+
+	// MixedScriptTokens lists patterns for tokens mixing scripts that are
+	// meant to be written without spaces, such as identifiers like
+	// "变量name", matched by "变量[A-Za-z]+". Like NoSpaceWords, no space is
+	// inserted inside a match, but its boundaries with the surrounding
+	// text are spaced as usual; unlike Protect, a match is not spaced as
+	// a whole.
+	MixedScriptTokens []*regexp.Regexp
+
+	// TabWidth is the distance between tab stops used when reporting
+	// columns, as in SpacingPositions. If it is 0, a tab counts as a
+	// single column like any other rune.
+	TabWidth int
+
+	// SpaceCallStrings makes the quoted arguments of inline function
+	// calls, like func("参数abc"), be spaced as well. By default such
+	// calls are kept as they are and only spaced against their
+	// surroundings.
+	SpaceCallStrings bool
+
+	// SkipCode, if not nil, makes lines that look like code according
+	// to it be left alone, while the other lines are spaced as usual.
+	SkipCode *CodeHeuristic
+
+	// Protect lists patterns for tokens that are kept as they are, such
+	// as inline regexps or globs like ".*" or "*.txt", which are easily
+	// mangled by the operator and symbol rules. Matches are spaced
+	// against adjacent CJK characters as if they were a single Latin
+	// word. Since such tokens can't be told apart from prose reliably,
+	// a pattern typically matches explicit delimiters, like "`[^`]+`".
+	Protect []*regexp.Regexp
+
+	// SpaceSymbols makes the symbols of the Miscellaneous Symbols and
+	// Dingbats blocks, like ☀, ★ and ✓, be spaced against adjacent CJK
+	// characters like half-width characters, so that "完成✓了" becomes
+	// "完成 ✓ 了". By default they are kept tight.
+	SpaceSymbols bool
+
+	// KeepCJKOperators makes operators between two CJK characters, as in
+	// 中文+中文, be left unspaced, since such text is clearly not code.
+	// Operators next to half-width characters are spaced as usual.
+	KeepCJKOperators bool
+
+	// SkipControls makes control characters, like NUL, be looked through
+	// when telling whether CJK and half-width characters are adjacent, so
+	// that "中文\x00abc" becomes "中文\x00 abc". By default they separate
+	// the characters around them like any other symbol. Either way they
+	// are kept as they are.
+	SkipControls bool
+
+	// SpaceBefore and SpaceAfter are inserted instead of a space where
+	// CJK is followed by a half-width character and where a half-width
+	// character is followed by CJK, respectively. They may hold any
+	// string, like a thin space, or nothing to leave that side unspaced.
+	// If both are empty, a regular space is inserted on both sides.
+	SpaceBefore string
+	SpaceAfter  string
+
+	// Markdown makes the delimiters of Markdown emphasis, like **bold**
+	// and _italic_, be kept out of the way of the rules. Spaces between
+	// emphasized text and its surroundings go outside the delimiters.
+	Markdown bool
+
+	// MaxLines and MaxBytes, if positive, make SpacingFile,
+	// SpacingPositions and SpacingOffsets stop reading after that many
+	// lines or bytes of input, as when previewing large files. A byte
+	// limit falling inside a multi-byte rune cuts right before it.
+	MaxLines int
+	MaxBytes int64
+
+	// CacheSize, if positive, makes SpacingText remember the results for
+	// up to that many distinct inputs, dropping the least recently used
+	// one when full. Repeated inputs then skip the rules entirely.
+	CacheSize int
+
+	// Metrics, if not nil, accumulates the number of calls, insertions,
+	// runs of and changes by each rule, and the time spent, of the
+	// Spacer.
+	Metrics *Metrics
+}
+
+// Spacer performs paranoid text spacing according to its Options.
+// A Spacer is safe for concurrent use by multiple goroutines.
+type Spacer struct {
+	rules        []rule
+	noSpaceWords *regexp.Regexp
+	mixedTokens  []*regexp.Regexp
+	protect      []*regexp.Regexp
+	symbols      bool
+	tabWidth     int
+	callStrings  bool
+	code         *codeMatcher
+	cache        *lru
+	before       string
+	after        string
+	asymmetric   bool
+	markdown     bool
+	cjkOperators bool
+	skipControls bool
+	maxLines     int
+	maxBytes     int64
+	metrics      *Metrics
+}
+
+var defaultSpacer, _ = NewSpacer(Options{})
+
+// NewSpacer returns a Spacer configured by opts.
+// It returns an error if opts names an unknown rule or code language.
+func NewSpacer(opts Options) (*Spacer, error) {
+	s := &Spacer{
+		noSpaceWords: wordsRegexp(opts.NoSpaceWords, opts.NoSpaceWordsIgnoreCase),
+		mixedTokens:  opts.MixedScriptTokens,
+		protect:      opts.Protect,
+		tabWidth:     opts.TabWidth,
+		symbols:      opts.SpaceSymbols,
+		callStrings:  opts.SpaceCallStrings,
+		before:       opts.SpaceBefore,
+		after:        opts.SpaceAfter,
+		asymmetric:   opts.SpaceBefore != "" || opts.SpaceAfter != "",
+		markdown:     opts.Markdown,
+		cjkOperators: opts.KeepCJKOperators,
+		skipControls: opts.SkipControls,
+		maxLines:     opts.MaxLines,
+		maxBytes:     opts.MaxBytes,
+		metrics:      opts.Metrics,
+	}
+
+	if opts.SkipCode != nil {
+		code, err := newCodeMatcher(opts.SkipCode)
+		if err != nil {
+			return nil, err
+		}
+		s.code = code
+	}
+
+	if opts.CacheSize > 0 {
+		s.cache = newLRU(opts.CacheSize)
+	}
+
+	if opts.NormalizeOnly {
+		s.rules = []rule{{"normalize", spacingNormalize}}
+		return s, nil
+	}
+
+	if opts.NormalizeExistingSpacing {
+		s.rules = append(s.rules, rule{"normalize", spacingNormalize})
+	}
+
+	if opts.ReplaceBoundaryTabs {
+		s.rules = append(s.rules, rule{"tab", spacingTab})
+	}
+
+	if len(opts.Rules) == 0 {
+		s.rules = append(s.rules, rules...)
+		return s, nil
+	}
+
+	for _, name := range opts.Rules {
+		r, ok := lookupRule(name)
+		if !ok {
+			return nil, fmt.Errorf("pangu: unknown rule %q", name)
+		}
+		s.rules = append(s.rules, r)
+	}
+
+	return s, nil
+}
+
+func lookupRule(name string) (rule, bool) {
+	for _, r := range rules {
+		if r.name == name {
+			return r, true
+		}
+	}
+
+	return rule{}, false
+}
+
+// SpacingText performs paranoid text spacing on text.
+// Each line is spaced on its own, so that no rule reaches across a line
+// break. It returns the processed text, with love.
+func (s *Spacer) SpacingText(text string) string {
+	if s.cache == nil {
+		return s.spacing(text, nil)
+	}
+
+	if result, ok := s.cache.get(text); ok {
+		return result
+	}
+	result := s.spacing(text, nil)
+	s.cache.add(text, result)
+
+	return result
+}
+
+// SpacingArgs is like the package-level SpacingArgs but uses the rules
+// and options of s.
+func (s *Spacer) SpacingArgs(args []string) []string {
+	if args == nil {
+		return nil
+	}
+
+	results := make([]string, len(args))
+	for i, arg := range args {
+		results[i] = s.SpacingText(arg)
+	}
+
+	return results
+}
+
+// spacing runs the rules of s on text, one line at a time, so that
+// spacing a line never depends on the lines around it, nor on the kind
+// of line break it ends with. If stats is not nil, the number of
+// changes made by each rule is added to it.
+func (s *Spacer) spacing(text string, stats map[string]int) string {
+	if s.metrics == nil {
+		return s.spacingLines(text, stats)
+	}
+
+	start := time.Now()
+	rules := map[string]int{}
+	result := s.spacingLines(text, rules)
+	s.metrics.add(time.Since(start), rules, insertions(diff(text, result)))
+
+	if stats != nil {
+		for name, n := range rules {
+			stats[name] += n
+		}
+	}
+
+	return result
+}
+
+// spacingLines is spacing without the metrics.
+func (s *Spacer) spacingLines(text string, stats map[string]int) string {
+	apply := s.applyRules
+	if s.markdown {
+		apply = s.applyMarkdown
+	}
+
+	lines := splitLines(text)
+	for i, line := range lines {
+		if s.code == nil || !s.code.match(line) {
+			lines[i] = apply(line, stats)
+		}
+	}
+
+	return strings.Join(lines, "")
+}
+
+// applyRules runs the rules of s on text, like spacing, without
+// looking for code.
+func (s *Spacer) applyRules(text string, stats map[string]int) string {
+	if len(text) < 2 {
+		return text
+	}
+
+	compileOnce.Do(compile)
+
+	original := text
+	var controls []cut
+	if s.skipControls || strings.ContainsAny(text, bidiOpeners+bidiClosers) {
+		text, controls = stripControls(text, s.skipControls)
+	}
+	stripped := text
+
+	if s.metrics != nil {
+		s.metrics.run(s.rules)
+	}
+
+	text, middles := protect(text, s.spans(text, stats))
+
+	for _, r := range s.rules {
+		if stats == nil {
+			text = r.apply(text)
+			continue
+		}
+
+		newText := r.apply(text)
+		if newText != text {
+			stats[r.name] += len(diff(text, newText))
+		}
+		text = newText
+	}
+	text = restore(text, middles)
+	if controls != nil {
+		text = reinsert(stripped, text, controls)
+	}
+
+	if s.cjkOperators {
+		text = unspaceCJKOperators(original, text)
+	}
+	if s.asymmetric {
+		text = s.respace(original, text)
+	}
+
+	return text
+}
+
+// splitLines slices text after each line break, which is "\n", "\r\n"
+// or a lone "\r", as left over by mixing line endings. Like
+// strings.SplitAfter, the last line is empty if text ends with a break.
+func splitLines(text string) []string {
+	var lines []string
+
+	for {
+		i := strings.IndexAny(text, "\r\n")
+		if i == -1 {
+			return append(lines, text)
+		}
+		if text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n' {
+			i++
+		}
+		lines = append(lines, text[:i+1])
+		text = text[i+1:]
+	}
+}
+
+// unspaceCJKOperators drops the spaces inserted into the original text
+// in spaced around operators between two CJK characters.
+func unspaceCJKOperators(original, spaced string) string {
+	var hunks []Hunk
+	for _, h := range diff(original, spaced) {
+		if h.Original != "" || !betweenCJK(original, h.Start) {
+			hunks = append(hunks, h)
+		}
+	}
+
+	return ApplyHunks(original, hunks)
+}
+
+// lastBase returns the last rune of text that is not IGN, or
+// utf8.RuneError if there is none.
+func lastBase(text string) rune {
+	for text != "" {
+		r, size := utf8.DecodeLastRuneInString(text)
+		if !unicode.Is(ignTable, r) {
+			return r
+		}
+		text = text[:len(text)-size]
+	}
+
+	return utf8.RuneError
+}
+
+// firstBase returns the first rune of text that is not IGN, or
+// utf8.RuneError if there is none.
+func firstBase(text string) rune {
+	for text != "" {
+		r, size := utf8.DecodeRuneInString(text)
+		if !unicode.Is(ignTable, r) {
+			return r
+		}
+		text = text[size:]
+	}
+
+	return utf8.RuneError
+}
+
+// betweenCJK reports whether text[i] starts, or text[:i] ends, a run of
+// operators with CJK characters on both sides.
+func betweenCJK(text string, i int) bool {
+	start, end := i, i
+	for end < len(text) && strings.IndexByte(operators, text[end]) != -1 {
+		end++
+	}
+	for start > 0 && strings.IndexByte(operators, text[start-1]) != -1 {
+		start--
+	}
+	if start == end {
+		return false
+	}
+
+	prev, _ := utf8.DecodeLastRuneInString(text[:start])
+	next, _ := utf8.DecodeRuneInString(text[end:])
+
+	return unicode.Is(cjkTable, prev) && unicode.Is(cjkTable, next)
+}
+
+// operators holds the operators spaced by the operator rule.
+const operators = "+-*/=&|<>"
+
+// respace replaces the spaces inserted into the original text in spaced
+// by the spacers of s, depending on which side the CJK character is.
+func (s *Spacer) respace(original, spaced string) string {
+	hunks := diff(original, spaced)
+	for i, h := range hunks {
+		if h.Original != "" {
+			continue
+		}
+
+		prev := lastBase(original[:h.Start])
+		next := firstBase(original[h.End:])
+		switch {
+		case unicode.Is(cjkTable, prev):
+			hunks[i].Spaced = s.before
+		case unicode.Is(cjkTable, next):
+			hunks[i].Spaced = s.after
+		}
+	}
+
+	return ApplyHunks(original, hunks)
+}
+
+// spans returns the spans of text that must not be spaced internally.
+// The changes made to quoted strings in calls are added to stats, as
+// in spacing.
+func (s *Spacer) spans(text string, stats map[string]int) []span {
+	var inner func(string) string
+	if s.callStrings {
+		inner = func(text string) string {
+			return s.spacingQuoted(text, stats)
+		}
+	}
+
+	all := [][]span{
+		atomicSpans(protected, text),
+		atomicSpans(s.protect, text),
+		matchSpans(s.noSpaceWords, text),
+		callSpans(text, inner),
+		literalSpans(text),
+	}
+	if s.symbols {
+		all = append(all, atomicSpans([]*regexp.Regexp{symbol}, text))
+	}
+	for _, re := range s.mixedTokens {
+		all = append(all, matchSpans(re, text))
+	}
+
+	return mergeSpans(all...)
+}
+
+// spacingQuoted performs paranoid text spacing on the contents of every
+// quoted string in text. They are part of the text being spaced, so
+// they are left out of the cache and the calls counted by the metrics.
+func (s *Spacer) spacingQuoted(text string, stats map[string]int) string {
+	return quoted_string.ReplaceAllStringFunc(text, func(q string) string {
+		return q[:1] + s.spacingLines(q[1:len(q)-1], stats) + q[len(q)-1:]
+	})
+}
+
+// SpacingFile reads the file named by filename, performs paranoid text
+// spacing on its contents and writes the processed content to w.
+// A successful call returns err == nil.
+func (s *Spacer) SpacingFile(filename string, w io.Writer) (err error) {
+	fr, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	return s.eachLine(fr, func(line string) error {
+		_, err := bw.WriteString(s.SpacingText(line))
+		return err
+	})
+}
+
+// eachLine reads r line by line and calls fn with each line, including
+// its newline, until EOF or the line or byte limit of s is reached. The
+// last line is passed to fn even if it is empty. It stops at the first
+// error returned by fn and returns it.
+func (s *Spacer) eachLine(r io.Reader, fn func(line string) error) error {
+	if s.maxBytes > 0 {
+		r = io.LimitReader(r, s.maxBytes)
+	}
+	br := bufio.NewReader(r)
+
+	for n := 1; ; n++ {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == io.EOF && s.maxBytes > 0 {
+			line = trimPartialRune(line)
+		}
+
+		if err := fn(line); err != nil {
+			return err
+		}
+
+		if err == io.EOF || n == s.maxLines {
+			return nil
+		}
+	}
+}
+
+// trimPartialRune removes the incomplete rune at the end of text, if
+// any, as left by cutting valid UTF-8 at an arbitrary byte.
+func trimPartialRune(text string) string {
+	for i := len(text) - 1; i >= 0 && i >= len(text)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(text[i]) {
+			if !utf8.FullRuneInString(text[i:]) {
+				return text[:i]
+			}
+			break
+		}
+	}
+
+	return text
+}
+
+// IsFileSpaced reports whether the file named by filename is already
+// fully spaced, that is, whether s.SpacingFile would leave it unchanged.
+// It stops reading at the first line that needs spacing.
+func (s *Spacer) IsFileSpaced(filename string) (bool, error) {
+	fr, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer fr.Close()
+
+	br := bufio.NewReader(fr)
+
+	err = sniff(br)
+	if err != nil {
+		return false, err
+	}
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+		if s.SpacingText(line) != line {
+			return false, nil
+		}
+		if err == io.EOF {
+			return true, nil
+		}
+	}
+}
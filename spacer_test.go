@@ -0,0 +1,318 @@
+package pangu_test
+
+import (
+	"bytes"
+	"github.com/vinta/pangu"
+	"regexp"
+	"strings"
+)
+
+func (suite *PanguTestSuite) TestDefaultRules() {
+	suite.Equal(pangu.DefaultRules(), []string{"quote", "hash", "operator", "bracket", "symbol", "ans"})
+
+	s, err := pangu.NewSpacer(pangu.Options{Rules: pangu.DefaultRules()})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(`陳上進+Vinta`), pangu.SpacingText(`陳上進+Vinta`))
+}
+
+func (suite *PanguTestSuite) TestSpacerRuleOrder() {
+	// Running "ans" before "operator" leaves nothing for the operator
+	// rule to do on the CJK side, so the operator is only spaced there.
+	s, err := pangu.NewSpacer(pangu.Options{Rules: []string{"quote", "hash", "ans", "bracket", "symbol", "operator"}})
+	suite.Nil(err)
+	suite.Equal(pangu.SpacingText(`陳上進+Vinta`), `陳上進 + Vinta`)
+	suite.Equal(s.SpacingText(`陳上進+Vinta`), `陳上進 +Vinta`)
+}
+
+func (suite *PanguTestSuite) TestSpacerRuleSubset() {
+	s, err := pangu.NewSpacer(pangu.Options{Rules: []string{"hash"}})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(`前面#H2G2後面`), `前面 #H2G2後面`)
+	suite.Equal(s.SpacingText(`前面#H2G2#後面`), `前面 #H2G2# 後面`)
+	suite.Equal(s.SpacingText(`前面H2G2後面`), `前面H2G2後面`)
+}
+
+func (suite *PanguTestSuite) TestSpacerUnknownRule() {
+	s, err := pangu.NewSpacer(pangu.Options{Rules: []string{"quote", "nope"}})
+	suite.Nil(s)
+	suite.EqualError(err, `pangu: unknown rule "nope"`)
+}
+
+func (suite *PanguTestSuite) TestBoundaryTabs() {
+	suite.Equal(pangu.SpacingText("中文\tEnglish"), "中文\tEnglish")
+	suite.Equal(pangu.SpacingText("English\t中文"), "English\t中文")
+	suite.Equal(pangu.SpacingText("名稱\t值\tvalue\t說明"), "名稱\t值\tvalue\t說明")
+	suite.Equal(pangu.SpacingText("\t中文English"), "\t中文 English")
+
+	s, err := pangu.NewSpacer(pangu.Options{ReplaceBoundaryTabs: true})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText("中文\tEnglish"), "中文 English")
+	suite.Equal(s.SpacingText("English\t中文"), "English 中文")
+	suite.Equal(s.SpacingText("名稱\t值\tvalue\t說明"), "名稱\t值 value 說明")
+	suite.Equal(s.SpacingText("名稱\t\tvalue"), "名稱\t\tvalue")
+	suite.Equal(s.SpacingText("\t中文English"), "\t中文 English")
+	suite.Equal(s.SpacingText("\t\tEnglish\t中文"), "\t\tEnglish 中文")
+}
+
+func (suite *PanguTestSuite) TestNoSpaceWords() {
+	// brand compounds are spaced by default
+	suite.Equal(pangu.SpacingText(`使用微信Pay付款`), `使用微信 Pay 付款`)
+	suite.Equal(pangu.SpacingText(`打开支付宝App`), `打开支付宝 App`)
+	suite.Equal(pangu.SpacingText(`我用Apple支付`), `我用 Apple 支付`)
+	suite.Equal(pangu.SpacingText(`Google地图很好用`), `Google 地图很好用`)
+
+	s, err := pangu.NewSpacer(pangu.Options{NoSpaceWords: []string{"微信Pay", "支付宝App", "Apple支付", "Google地图"}})
+	suite.Nil(err)
+
+	// CJK followed by Latin
+	suite.Equal(s.SpacingText(`微信Pay`), `微信Pay`)
+	suite.Equal(s.SpacingText(`使用微信Pay付款`), `使用微信Pay 付款`)
+	suite.Equal(s.SpacingText(`打开支付宝App`), `打开支付宝App`)
+
+	// Latin followed by CJK
+	suite.Equal(s.SpacingText(`Apple支付`), `Apple支付`)
+	suite.Equal(s.SpacingText(`我用Apple支付`), `我用 Apple支付`)
+	suite.Equal(s.SpacingText(`Google地图很好用`), `Google地图很好用`)
+
+	// other boundaries are still spaced
+	suite.Equal(s.SpacingText(`微信Pay和WeChat Pay`), `微信Pay 和 WeChat Pay`)
+	suite.Equal(s.SpacingText(`用Pay付`), `用 Pay 付`)
+	suite.Equal(s.SpacingText(`使用微信Pay 付款`), `使用微信Pay 付款`)
+}
+
+func (suite *PanguTestSuite) TestNoSpaceWordsCase() {
+	// case-sensitive by default
+	s, err := pangu.NewSpacer(pangu.Options{NoSpaceWords: []string{"iOS版"}})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(`下载iOS版应用`), `下载 iOS版应用`)
+	suite.Equal(s.SpacingText(`下载IOS版应用`), `下载 IOS 版应用`)
+	suite.Equal(s.SpacingText(`下载ios版应用`), `下载 ios 版应用`)
+
+	s, err = pangu.NewSpacer(pangu.Options{NoSpaceWords: []string{"iOS版"}, NoSpaceWordsIgnoreCase: true})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(`下载iOS版应用`), `下载 iOS版应用`)
+	suite.Equal(s.SpacingText(`下载IOS版应用`), `下载 IOS版应用`)
+	suite.Equal(s.SpacingText(`下载ios版应用`), `下载 ios版应用`)
+	suite.Equal(s.SpacingText(`下载Android版应用`), `下载 Android 版应用`)
+}
+
+func (suite *PanguTestSuite) TestMixedScriptTokens() {
+	s, err := pangu.NewSpacer(pangu.Options{MixedScriptTokens: []*regexp.Regexp{regexp.MustCompile(`变量[A-Za-z]+`)}})
+	suite.Nil(err)
+
+	suite.Equal(pangu.SpacingText(`设置变量name为1`), `设置变量 name 为 1`)
+	suite.Equal(s.SpacingText(`设置变量name为1`), `设置变量name 为 1`)
+	suite.Equal(s.SpacingText(`设置变量count和变量total`), `设置变量count 和变量total`)
+	suite.Equal(s.SpacingText(`把变量name传给Go函数`), `把变量name 传给 Go 函数`)
+}
+
+func (suite *PanguTestSuite) TestSpaceCallStrings() {
+	suite.Equal(pangu.SpacingText(`运行func("参数abc")后`), `运行 func("参数abc") 后`)
+
+	s, err := pangu.NewSpacer(pangu.Options{SpaceCallStrings: true})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(`运行func("参数abc")后`), `运行 func("参数 abc") 后`)
+	suite.Equal(s.SpacingText(`运行func("参数", '中文abc')后`), `运行 func("参数", '中文 abc') 后`)
+	suite.Equal(s.SpacingText(`运行func("参数")后`), `运行 func("参数") 后`)
+}
+
+func (suite *PanguTestSuite) TestProtect() {
+	s, err := pangu.NewSpacer(pangu.Options{Protect: []*regexp.Regexp{
+		regexp.MustCompile(`/[^/\s]+/`),
+		regexp.MustCompile(`\.\*|\*\.[a-z]+`),
+	}})
+	suite.Nil(err)
+
+	// explicitly delimited regexps are kept as they are
+	suite.Equal(pangu.SpacingText(`匹配/^中文+$/的行`), `匹配 /^ 中文 +$/ 的行`)
+	suite.Equal(s.SpacingText(`匹配/^中文+$/的行`), `匹配 /^中文+$/ 的行`)
+	suite.Equal(s.SpacingText(`/中文/`), `/中文/`)
+
+	// and so are bare tokens, spaced as words against CJK
+	suite.Equal(pangu.SpacingText(`匹配.*结尾`), `匹配.* 结尾`)
+	suite.Equal(s.SpacingText(`匹配.*结尾`), `匹配 .* 结尾`)
+	suite.Equal(s.SpacingText(`通配符*.txt文件`), `通配符 *.txt 文件`)
+	suite.Equal(s.SpacingText(`用*.go和*.txt文件`), `用 *.go 和 *.txt 文件`)
+	suite.Equal(s.SpacingText(`匹配 .* 结尾`), `匹配 .* 结尾`)
+
+	// the rest of the text is spaced as usual
+	suite.Equal(s.SpacingText(`中文abc`), `中文 abc`)
+}
+
+func (suite *PanguTestSuite) TestAsymmetricSpaces() {
+	// a four-per-em space before Latin, none after
+	s, err := pangu.NewSpacer(pangu.Options{SpaceBefore: "\u2005"})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(`當你凝視著bug，bug也凝視著你`), "當你凝視著\u2005bug，bug也凝視著你")
+	suite.Equal(s.SpacingText(`陳上進+Vinta`), "陳上進\u2005+ Vinta")
+
+	// thin spaces on both sides
+	s, err = pangu.NewSpacer(pangu.Options{SpaceBefore: "\u2009", SpaceAfter: "\u2009"})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(`與PM戰鬥的人`), "與\u2009PM\u2009戰鬥的人")
+	suite.Equal(s.SpacingText(`前面#H2G2後面`), "前面\u2009#H2G2\u2009後面")
+
+	// existing spaces are left alone
+	suite.Equal(s.SpacingText(`與 PM戰鬥的人`), "與 PM\u2009戰鬥的人")
+
+	// ZWJs, variation selectors and combining marks after CJK
+	s, err = pangu.NewSpacer(pangu.Options{SpaceBefore: "\u2009"})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText("中文\u200dEnglish"), "中文\u200d\u2009English")
+	suite.Equal(s.SpacingText("字\ufe00text"), "字\ufe00\u2009text")
+	suite.Equal(s.SpacingText("中文\u0301English"), "中文\u0301\u2009English")
+
+	// multi-rune spacers
+	s, err = pangu.NewSpacer(pangu.Options{SpaceBefore: "&nbsp;", SpaceAfter: "&#8197;"})
+	suite.Nil(err)
+	suite.Equal(s.SpacingText(`與PM戰鬥的人`), "與&nbsp;PM&#8197;戰鬥的人")
+}
+
+func (suite *PanguTestSuite) TestSpaceSymbols() {
+	s, err := pangu.NewSpacer(pangu.Options{SpaceSymbols: true})
+	suite.Nil(err)
+
+	// off by default
+	suite.Equal(pangu.SpacingText(`完成✓了`), `完成✓了`)
+	suite.Equal(pangu.SpacingText(`今天☀天氣好`), `今天☀天氣好`)
+
+	// Dingbats
+	suite.Equal(s.SpacingText(`完成✓了`), `完成 ✓ 了`)
+	suite.Equal(s.SpacingText(`失敗✗了`), `失敗 ✗ 了`)
+	suite.Equal(s.SpacingText(`剪刀✂工具`), `剪刀 ✂ 工具`)
+
+	// Miscellaneous Symbols
+	suite.Equal(s.SpacingText(`評分★★★的電影`), `評分 ★★★ 的電影`)
+	suite.Equal(s.SpacingText(`今天☀天氣好`), `今天 ☀ 天氣好`)
+	suite.Equal(s.SpacingText("今天\u2600\ufe0f天氣好"), "今天 \u2600\ufe0f 天氣好")
+	suite.Equal(s.SpacingText(`完成 ✓ 了`), `完成 ✓ 了`)
+	suite.Equal(s.SpacingText(`✓abc`), `✓abc`)
+}
+
+func (suite *PanguTestSuite) TestKeepCJKOperators() {
+	s, err := pangu.NewSpacer(pangu.Options{KeepCJKOperators: true})
+	suite.Nil(err)
+
+	// CJK on both sides
+	suite.Equal(pangu.SpacingText(`中文+中文`), `中文 + 中文`)
+	suite.Equal(s.SpacingText(`中文+中文`), `中文+中文`)
+	suite.Equal(s.SpacingText(`中文==中文`), `中文==中文`)
+	suite.Equal(s.SpacingText(`甲+乙=丙`), `甲+乙=丙`)
+	suite.Equal(s.SpacingText(`前面 + 後面`), `前面 + 後面`)
+
+	// CJK on one side only
+	suite.Equal(s.SpacingText(`陳上進+Vinta`), `陳上進 + Vinta`)
+	suite.Equal(s.SpacingText(`Vinta+陳上進`), `Vinta + 陳上進`)
+	suite.Equal(s.SpacingText(`中文-Vinta-中文`), `中文 - Vinta - 中文`)
+	suite.Equal(s.SpacingText(`得到一個A+B的結果`), `得到一個 A+B 的結果`)
+}
+
+func (suite *PanguTestSuite) TestNormalizeExistingSpacing() {
+	s, err := pangu.NewSpacer(pangu.Options{NormalizeExistingSpacing: true})
+	suite.Nil(err)
+
+	// Before CJK punctuation
+	suite.Equal(pangu.SpacingText(`中文 ，然後`), `中文 ，然後`)
+	suite.Equal(s.SpacingText(`中文 ，然後`), `中文，然後`)
+	suite.Equal(s.SpacingText(`使用Go 。`), `使用 Go。`)
+	suite.Equal(s.SpacingText(`真的嗎 ？！`), `真的嗎？！`)
+	suite.Equal(s.SpacingText(`甲 、乙 、丙`), `甲、乙、丙`)
+
+	// Before closing brackets
+	suite.Equal(s.SpacingText(`（ 中文 ）`), `（中文）`)
+	suite.Equal(s.SpacingText(`「 Vinta 」說`), `「Vinta」說`)
+	suite.Equal(s.SpacingText(`書名《 Go 語言 》`), `書名《Go 語言》`)
+
+	// Indentation before closing punctuation
+	suite.Equal(s.SpacingText("\t）缩进"), "\t）缩进")
+	suite.Equal(s.SpacingText("    」"), "    」")
+	suite.Equal(s.SpacingText("  ，然後 ，"), "  ，然後，")
+
+	// Runs of spaces
+	suite.Equal(s.SpacingText(`中文  abc   中文`), `中文 abc 中文`)
+	suite.Equal(s.SpacingText(`abc  def`), `abc  def`)
+}
+
+func (suite *PanguTestSuite) TestNormalizeOnly() {
+	s, err := pangu.NewSpacer(pangu.Options{NormalizeOnly: true})
+	suite.Nil(err)
+
+	// anomalies are cleaned
+	suite.Equal(s.SpacingText(`中文 ，然後`), `中文，然後`)
+	suite.Equal(s.SpacingText(`（ 中文 ）`), `（中文）`)
+	suite.Equal(s.SpacingText(`中文  abc   中文`), `中文 abc 中文`)
+
+	// indentation is not an anomaly
+	suite.Equal(s.SpacingText("\t）缩进"), "\t）缩进")
+	suite.Equal(s.SpacingText("    」Go"), "    」Go")
+
+	// but no space is inserted
+	for _, text := range []string{
+		`當你凝視著bug，bug也凝視著你`,
+		`前面(中文123漢字)後面`,
+		`使用Go 。`,
+		`中文+中文`,
+	} {
+		result := s.SpacingTextResult(text)
+		suite.Equal(result.Insertions, 0)
+	}
+	suite.Equal(s.SpacingText(`使用Go 。`), `使用Go。`)
+	suite.Equal(s.SpacingText(`當你凝視著bug`), `當你凝視著bug`)
+}
+
+func (suite *PanguTestSuite) TestSkipControls() {
+	s, err := pangu.NewSpacer(pangu.Options{SkipControls: true})
+	suite.Nil(err)
+
+	suite.Equal(s.SpacingText("中文\x00abc"), "中文\x00 abc")
+	suite.Equal(s.SpacingText("中文\u202a\x00abc\u202c中文"), "中文 \u202a\x00abc\u202c 中文")
+	suite.Equal(s.SpacingText("abc\x00中文"), "abc\x00 中文")
+	suite.Equal(s.SpacingText("中文\x01\x02abc"), "中文\x01\x02 abc")
+	suite.Equal(s.SpacingText("與\x00PM\x1f戰鬥的人"), "與\x00 PM\x1f 戰鬥的人")
+	suite.Equal(s.SpacingText("\x00中文abc\x7f"), "\x00中文 abc\x7f")
+	suite.Equal(s.SpacingText("中\x00文"), "中\x00文")
+	suite.Equal(s.SpacingText("中文\x00 abc"), "中文\x00 abc")
+
+	// whitespace is not skipped
+	suite.Equal(s.SpacingText("中文\tabc"), "中文\tabc")
+}
+
+func (suite *PanguTestSuite) TestMaxBytes() {
+	// "Sephiroth見" is 12 bytes, the 13th is the first of "到"
+	s, err := pangu.NewSpacer(pangu.Options{MaxBytes: 13})
+	suite.Nil(err)
+
+	var buf bytes.Buffer
+	err = s.SpacingFile("_fixtures/test_file.txt", &buf)
+	suite.Nil(err)
+	suite.Equal(buf.String(), "Sephiroth 見")
+
+	s, err = pangu.NewSpacer(pangu.Options{MaxBytes: 15})
+	suite.Nil(err)
+
+	buf.Reset()
+	var offsets []int64
+	err = s.SpacingOffsets(strings.NewReader("中文abc\n中文abc"), &buf, func(offset int64) {
+		offsets = append(offsets, offset)
+	})
+	suite.Nil(err)
+	suite.Equal(buf.String(), "中文 abc\n中")
+	suite.Equal(offsets, []int64{6})
+}
+
+func (suite *PanguTestSuite) TestMaxLines() {
+	s, err := pangu.NewSpacer(pangu.Options{MaxLines: 2})
+	suite.Nil(err)
+
+	var buf bytes.Buffer
+	err = s.SpacingOffsets(strings.NewReader("中文abc\n\n中文abc\n"), &buf, func(int64) {})
+	suite.Nil(err)
+	suite.Equal(buf.String(), "中文 abc\n\n")
+
+	var positions []pangu.Position
+	err = s.SpacingPositions(strings.NewReader("中文abc\nabc中文\n中文abc"), func(pos pangu.Position) {
+		positions = append(positions, pos)
+	})
+	suite.Nil(err)
+	suite.Equal(positions, []pangu.Position{{Line: 1, Column: 3}, {Line: 2, Column: 4}})
+}
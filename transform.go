@@ -0,0 +1,111 @@
+package pangu
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/transform"
+)
+
+// transformBufSize is the size of the buffers used by transform.Reader
+// and transform.Writer. A line that doesn't fit in it is split.
+const transformBufSize = 4096
+
+// Transformer returns a transform.Transformer that performs paranoid
+// text spacing on a stream, one line at a time, in the same way as
+// SpacingFile.
+func Transformer() transform.Transformer {
+	return defaultSpacer.Transformer()
+}
+
+// WidthTransformer is like Transformer, but it also converts full-width
+// forms as NormalizeWidth does before spacing each line. The output is
+// the same as SpacingText(NormalizeWidth(line)) for every line.
+func WidthTransformer() transform.Transformer {
+	return defaultSpacer.WidthTransformer()
+}
+
+// Transformer is like the package-level Transformer but uses the rules
+// and options of s.
+func (s *Spacer) Transformer() transform.Transformer {
+	return &transformer{spacer: s}
+}
+
+// WidthTransformer is like the package-level WidthTransformer but uses
+// the rules and options of s.
+func (s *Spacer) WidthTransformer() transform.Transformer {
+	return &transformer{spacer: s, width: true}
+}
+
+type transformer struct {
+	spacer  *Spacer
+	width   bool
+	pending []byte // the end of a spaced line that didn't fit in dst
+}
+
+func (t *transformer) Reset() {
+	t.pending = nil
+}
+
+func (t *transformer) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	if len(t.pending) > 0 {
+		nDst = copy(dst, t.pending)
+		t.pending = t.pending[nDst:]
+		if len(t.pending) > 0 {
+			return nDst, 0, transform.ErrShortDst
+		}
+		t.pending = nil
+	}
+
+	for nSrc < len(src) {
+		end := len(src)
+		if i := bytes.IndexByte(src[nSrc:], '\n'); i != -1 {
+			end = nSrc + i + 1
+		} else if !atEOF {
+			if nSrc > 0 || len(src) < transformBufSize {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			end = splitLine(src)
+		}
+
+		line := string(src[nSrc:end])
+		if t.width {
+			line = NormalizeWidth(line)
+		}
+		line = t.spacer.SpacingText(line)
+
+		n := copy(dst[nDst:], line)
+		nDst += n
+		nSrc = end
+		if n < len(line) {
+			// The line is consumed anyway, since it may be longer
+			// than any dst; the rest of it goes first next time.
+			t.pending = []byte(line[n:])
+			return nDst, nSrc, transform.ErrShortDst
+		}
+	}
+
+	return nDst, nSrc, nil
+}
+
+// splitLine returns where to cut src, a line too long for the buffer,
+// preferably right after its last whitespace.
+func splitLine(src []byte) int {
+	i := bytes.LastIndexFunc(src, unicode.IsSpace)
+	if i != -1 {
+		_, size := utf8.DecodeRune(src[i:])
+		return i + size
+	}
+
+	// No whitespace at all, so cut before the last, maybe partial, rune.
+	i = len(src) - 1
+	for i > 0 && !utf8.RuneStart(src[i]) {
+		i--
+	}
+	if i == 0 {
+		return len(src)
+	}
+
+	return i
+}
@@ -0,0 +1,71 @@
+package pangu_test
+
+import (
+	"bytes"
+	"github.com/vinta/pangu"
+	"golang.org/x/text/transform"
+	"io/ioutil"
+	"strings"
+)
+
+func (suite *PanguTestSuite) TestTransformer() {
+	text := "當你凝視著bug，bug也凝視著你\n與PM戰鬥的人，應當小心自己不要成為PM\n前面#H2G2後面"
+
+	result, _, err := transform.String(pangu.Transformer(), text)
+	suite.Nil(err)
+	suite.Equal(result, "當你凝視著 bug，bug 也凝視著你\n與 PM 戰鬥的人，應當小心自己不要成為 PM\n前面 #H2G2 後面")
+}
+
+func (suite *PanguTestSuite) TestWidthTransformer() {
+	texts := []string{
+		"使用ＡＰＩ開發，Ｃ＋＋也可以",
+		"新八的構造成分有９５％是眼鏡\n得到一個Ａ＝Ｂ的結果\n",
+		"（中文ＯＫ）說明",
+	}
+
+	for _, text := range texts {
+		var expected []string
+		for _, line := range strings.SplitAfter(text, "\n") {
+			expected = append(expected, pangu.SpacingText(pangu.NormalizeWidth(line)))
+		}
+
+		result, _, err := transform.String(pangu.WidthTransformer(), text)
+		suite.Nil(err)
+		suite.Equal(result, strings.Join(expected, ""))
+	}
+
+	result, _, err := transform.String(pangu.WidthTransformer(), "使用ＡＰＩ開發")
+	suite.Nil(err)
+	suite.Equal(result, "使用 API 開發")
+}
+
+func (suite *PanguTestSuite) TestTransformerReader() {
+	data, err := ioutil.ReadFile("_fixtures/test_file.txt")
+	checkError(err)
+
+	// long lines, so transform.Reader's buffer fills up before a newline
+	line := strings.Replace(string(data), "\n", " ", -1)
+	text := strings.Repeat(line, 100) + "\n" + string(data)
+
+	r := transform.NewReader(strings.NewReader(text), pangu.Transformer())
+	result, err := ioutil.ReadAll(r)
+	suite.Nil(err)
+	suite.Equal(string(result), pangu.SpacingText(text))
+
+	// a spaced line longer than the buffer of transform.Reader
+	text = strings.Repeat("中a", 800) + "\n" + string(data)
+	r = transform.NewReader(strings.NewReader(text), pangu.Transformer())
+	result, err = ioutil.ReadAll(r)
+	suite.Nil(err)
+	suite.Equal(string(result), pangu.SpacingText(text))
+
+	var buf bytes.Buffer
+	w := transform.NewWriter(&buf, pangu.Transformer())
+	_, err = w.Write(data)
+	suite.Nil(err)
+	suite.Nil(w.Close())
+
+	expected, err := ioutil.ReadFile("_fixtures/test_file.expected.txt")
+	checkError(err)
+	suite.Equal(buf.String(), string(expected))
+}
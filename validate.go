@@ -0,0 +1,135 @@
+package pangu
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Issue is a place where text isn't spaced the way SpacingText would
+// space it.
+type Issue struct {
+	Offset  int      // byte offset into the input
+	Pos     Position // line and column of Offset
+	Message string   // e.g. "missing space between CJK and Latin"
+}
+
+// Validate reports every spacing issue in input, in order. It returns
+// nil if input is already fully spaced.
+func Validate(input string) []Issue {
+	return defaultSpacer.Validate(input)
+}
+
+// Validate is like the package-level Validate but uses the rules and
+// options of s.
+func (s *Spacer) Validate(input string) []Issue {
+	var issues []Issue
+
+	i, pos := 0, Position{Line: 1, Column: 1}
+	for _, h := range s.SpacingHunks(input) {
+		for i < h.Start {
+			r, size := utf8.DecodeRuneInString(input[i:])
+			if r == '\n' {
+				pos = Position{Line: pos.Line + 1, Column: 1}
+			} else {
+				pos.Column = s.advance(pos.Column, r)
+			}
+			i += size
+		}
+
+		issues = append(issues, Issue{
+			Offset:  h.Start,
+			Pos:     pos,
+			Message: describe(input, h),
+		})
+	}
+
+	return issues
+}
+
+// CheckNoDataLoss performs paranoid text spacing on input and checks
+// that only whitespace was changed, that is, that input and its spaced
+// form are the same once all whitespace is taken out. It returns an
+// error locating the first other change, if any.
+func CheckNoDataLoss(input string) error {
+	return defaultSpacer.CheckNoDataLoss(input)
+}
+
+// CheckNoDataLoss is like the package-level CheckNoDataLoss but uses the
+// rules and options of s. Non-whitespace SpaceBefore or SpaceAfter
+// strings are reported as changes.
+func (s *Spacer) CheckNoDataLoss(input string) error {
+	output := s.SpacingText(input)
+
+	i, j := 0, 0
+	for {
+		i = skipSpace(input, i)
+		j = skipSpace(output, j)
+		if i == len(input) && j == len(output) {
+			return nil
+		}
+
+		ri, ni := utf8.DecodeRuneInString(input[i:])
+		rj, nj := utf8.DecodeRuneInString(output[j:])
+		if i == len(input) || j == len(output) || ri != rj {
+			return fmt.Errorf("pangu: non-space content changed at byte %d", i)
+		}
+		i += ni
+		j += nj
+	}
+}
+
+// skipSpace returns the index of the first non-whitespace rune of text
+// at or after i, or len(text).
+func skipSpace(text string, i int) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		i += size
+	}
+
+	return i
+}
+
+// describe explains what is wrong with the region of text covered by h.
+func describe(text string, h Hunk) string {
+	before, _ := utf8.DecodeLastRuneInString(text[:h.Start])
+	after, _ := utf8.DecodeRuneInString(text[h.End:])
+	between := kindOf(before) + " and " + kindOf(after)
+
+	switch {
+	case h.Original == "":
+		return "missing space between " + between
+	case h.Spaced == "":
+		return "unexpected space between " + between
+	case strings.TrimLeft(h.Original, "\t") == "":
+		return "tab instead of space between " + between
+	}
+
+	return fmt.Sprintf("%q instead of %q between %s", h.Original, h.Spaced, between)
+}
+
+// kindOf names the kind of character r is, as used in Issue messages.
+func kindOf(r rune) string {
+	switch {
+	case unicode.Is(cjkTable, r):
+		return "CJK"
+	case strings.ContainsRune("\"'“”", r):
+		return "quote"
+	case strings.ContainsRune("()[]{}<>", r):
+		return "bracket"
+	case strings.ContainsRune("+-*/=&|", r):
+		return "operator"
+	case strings.ContainsRune("~!;:,.?…", r):
+		return "punctuation"
+	case unicode.IsDigit(r):
+		return "number"
+	case unicode.IsLetter(r):
+		return "Latin"
+	}
+
+	return "symbol"
+}
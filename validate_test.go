@@ -0,0 +1,54 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+	"io/ioutil"
+)
+
+func (suite *PanguTestSuite) TestValidate() {
+	text := "當你凝視著bug，bug也凝視著你\n" +
+		"價格=100元\n" +
+		"前面( 中文 )後面"
+
+	suite.Equal(pangu.Validate(text), []pangu.Issue{
+		{Offset: 15, Pos: pangu.Position{Line: 1, Column: 6}, Message: "missing space between CJK and Latin"},
+		{Offset: 24, Pos: pangu.Position{Line: 1, Column: 13}, Message: "missing space between Latin and CJK"},
+		{Offset: 46, Pos: pangu.Position{Line: 2, Column: 3}, Message: "missing space between CJK and operator"},
+		{Offset: 47, Pos: pangu.Position{Line: 2, Column: 4}, Message: "missing space between operator and number"},
+		{Offset: 50, Pos: pangu.Position{Line: 2, Column: 7}, Message: "missing space between number and CJK"},
+		{Offset: 60, Pos: pangu.Position{Line: 3, Column: 3}, Message: "missing space between CJK and bracket"},
+		{Offset: 61, Pos: pangu.Position{Line: 3, Column: 4}, Message: "unexpected space between bracket and CJK"},
+		{Offset: 68, Pos: pangu.Position{Line: 3, Column: 7}, Message: "unexpected space between CJK and bracket"},
+		{Offset: 70, Pos: pangu.Position{Line: 3, Column: 9}, Message: "missing space between bracket and CJK"},
+	})
+}
+
+func (suite *PanguTestSuite) TestValidateSpaced() {
+	suite.Nil(pangu.Validate("與 PM 戰鬥的人\n當你凝視著 bug，bug 也凝視著你"))
+}
+
+func (suite *PanguTestSuite) TestValidateTab() {
+	s, err := pangu.NewSpacer(pangu.Options{ReplaceBoundaryTabs: true})
+	suite.Nil(err)
+	suite.Equal(s.Validate("中文\tEnglish"), []pangu.Issue{
+		{Offset: 6, Pos: pangu.Position{Line: 1, Column: 3}, Message: "tab instead of space between CJK and Latin"},
+	})
+}
+
+func (suite *PanguTestSuite) TestCheckNoDataLoss() {
+	suite.Nil(pangu.CheckNoDataLoss(""))
+	suite.Nil(pangu.CheckNoDataLoss("當你凝視著bug，bug也凝視著你\n與 PM 戰鬥的人"))
+	suite.Nil(pangu.CheckNoDataLoss(`前面( 中文 )後面`))
+	suite.Nil(pangu.CheckNoDataLoss(`前面#H2G2後面，價格=100元`))
+
+	fixture, err := ioutil.ReadFile("_fixtures/test_file.txt")
+	suite.Nil(err)
+	suite.Nil(pangu.CheckNoDataLoss(string(fixture)))
+}
+
+func (suite *PanguTestSuite) TestCheckNoDataLossChanged() {
+	s, err := pangu.NewSpacer(pangu.Options{SpaceBefore: "&nbsp;", SpaceAfter: " "})
+	suite.Nil(err)
+	suite.EqualError(s.CheckNoDataLoss(`與PM戰鬥的人`), "pangu: non-space content changed at byte 3")
+	suite.Nil(s.CheckNoDataLoss(`與 PM戰鬥的人`))
+}
@@ -0,0 +1,33 @@
+package pangu
+
+import (
+	"strings"
+)
+
+// NormalizeWidth converts full-width Latin letters, digits and the
+// full-width forms of half-width symbols in text to their ASCII
+// counterparts, e.g. "ＡＢＣ１２３" to "ABC123".
+//
+// Full-width punctuation that is also used in CJK text, such as
+// "，", "：", "！", "？" or "（）", is left alone.
+func NormalizeWidth(text string) string {
+	return strings.Map(narrow, text)
+}
+
+// narrow returns the ASCII counterpart of the full-width rune r, or r
+// itself if it should stay full-width.
+func narrow(r rune) rune {
+	switch {
+	case r >= '０' && r <= '９',
+		r >= 'Ａ' && r <= 'Ｚ',
+		r >= 'ａ' && r <= 'ｚ':
+		return r - 0xfee0
+	}
+
+	switch r {
+	case '＃', '＄', '％', '＆', '＊', '＋', '－', '／', '＜', '＝', '＞', '＠', '＼', '＾', '＿', '｀', '｜':
+		return r - 0xfee0
+	}
+
+	return r
+}
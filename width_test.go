@@ -0,0 +1,12 @@
+package pangu_test
+
+import (
+	"github.com/vinta/pangu"
+)
+
+func (suite *PanguTestSuite) TestNormalizeWidth() {
+	suite.Equal(pangu.NormalizeWidth(`ＡＢＣｘｙｚ０１２３`), `ABCxyz0123`)
+	suite.Equal(pangu.NormalizeWidth(`１００％，Ｃ＋＋！`), `100%，C++！`)
+	suite.Equal(pangu.NormalizeWidth(`（中文：ＯＫ？）`), `（中文：OK？）`)
+	suite.Equal(pangu.NormalizeWidth(`abc中文`), `abc中文`)
+}